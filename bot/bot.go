@@ -0,0 +1,635 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bot 用于管理 Bot：创建、更新、发布 Bot，查询空间中已发布的 Bot 以及 Bot 的配置信息。
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/chenmingyong0423/go-coze/common/pager"
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/chenmingyong0423/go-coze/common/response"
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	InternationalCreateUrl        = "https://api.coze.com/v1/bot/create"
+	InternationalUpdateUrl        = "https://api.coze.com/v1/bot/update"
+	InternationalPublishUrl       = "https://api.coze.com/v1/bot/publish"
+	InternationalListPublishedUrl = "https://api.coze.com/v1/space/published_bots_list"
+	InternationalRetrieveUrl      = "https://api.coze.com/v1/bot/get_online_info"
+
+	createUrl             = "https://api.coze.cn/v1/bot/create"
+	updateUrl             = "https://api.coze.cn/v1/bot/update"
+	publishUrl            = "https://api.coze.cn/v1/bot/publish"
+	listPublishedUrl      = "https://api.coze.cn/v1/space/published_bots_list"
+	retrieveUrl           = "https://api.coze.cn/v1/bot/get_online_info"
+	HeaderAuthorization   = "authorization"
+	HeaderContentType     = "Content-Type"
+	HeaderApplicationJson = "application/json"
+
+	// ConnectorIdAPI 发布为 API 服务的渠道 ID。
+	ConnectorIdAPI = "1024"
+	// ConnectorIdWebSDK 发布为 Web SDK 的渠道 ID。
+	ConnectorIdWebSDK = "999"
+
+	// DefaultListPageSize 查询已发布的 Bot 列表时默认的每页数量。
+	DefaultListPageSize = 20
+)
+
+type Bot struct {
+	authorization string
+}
+
+func NewBot(authorization string) *Bot {
+	return &Bot{authorization: authorization}
+}
+
+func (b *Bot) CreateRequest(spaceId string) *CreateRequest {
+	return &CreateRequest{bot: b, SpaceId: spaceId}
+}
+
+func (b *Bot) UpdateRequest() *UpdateRequest {
+	return &UpdateRequest{bot: b}
+}
+
+func (b *Bot) PublishRequest() *PublishRequest {
+	return &PublishRequest{bot: b, ConnectorIds: []string{ConnectorIdAPI}}
+}
+
+func (b *Bot) ListPublishedRequest(spaceId string) *ListPublishedRequest {
+	return &ListPublishedRequest{
+		bot:       b,
+		spaceId:   spaceId,
+		pageIndex: 1,
+		pageSize:  DefaultListPageSize,
+	}
+}
+
+func (b *Bot) RetrieveRequest() *RetrieveRequest {
+	return &RetrieveRequest{bot: b}
+}
+
+// CreateRequest 在空间中创建 Bot，创建后需要发布才能通过 API 使用。
+type CreateRequest struct {
+	bot *Bot
+
+	timeout time.Duration
+	// 是否跳过发送请求前的参数校验。
+	skipValidation bool
+
+	SpaceId     string `json:"space_id"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	IconFileId  string `json:"icon_file_id,omitempty"`
+
+	PromptInfo      *request.BotPromptInfo      `json:"prompt_info,omitempty"`
+	OnboardingInfo  *request.BotOnboardingInfo  `json:"onboarding_info,omitempty"`
+	PluginIdList    *request.BotPluginIdList    `json:"plugin_id_list,omitempty"`
+	ModelInfoConfig *request.BotModelInfoConfig `json:"model_info_config,omitempty"`
+}
+
+func (r *CreateRequest) WithTimeout(timeout time.Duration) *CreateRequest {
+	r.timeout = timeout
+	return r
+}
+
+// WithSkipValidation 设置是否跳过 Do 发送请求前的参数校验。
+func (r *CreateRequest) WithSkipValidation(skip bool) *CreateRequest {
+	r.skipValidation = skip
+	return r
+}
+
+func (r *CreateRequest) WithName(name string) *CreateRequest {
+	r.Name = name
+	return r
+}
+
+func (r *CreateRequest) WithDescription(description string) *CreateRequest {
+	r.Description = description
+	return r
+}
+
+// WithIconFileId 设置 Bot 的头像，fileId 为通过 file 包上传图片得到的文件 ID。
+func (r *CreateRequest) WithIconFileId(fileId string) *CreateRequest {
+	r.IconFileId = fileId
+	return r
+}
+
+// WithPrompt 设置 Bot 的人设与回复逻辑。
+func (r *CreateRequest) WithPrompt(prompt string) *CreateRequest {
+	r.PromptInfo = &request.BotPromptInfo{Prompt: prompt}
+	return r
+}
+
+// WithOnboarding 设置 Bot 的开场白和预置问题。
+func (r *CreateRequest) WithOnboarding(prologue string, suggestedQuestions ...string) *CreateRequest {
+	r.OnboardingInfo = &request.BotOnboardingInfo{Prologue: prologue, SuggestedQuestions: suggestedQuestions}
+	return r
+}
+
+// WithPlugins 设置 Bot 使用的插件工具，会整体替换原有的插件，不传参数时清空所有插件。
+func (r *CreateRequest) WithPlugins(plugins ...request.BotPluginIdInfo) *CreateRequest {
+	if plugins == nil {
+		// 以空数组而不是 null 发送，服务端才会清空插件。
+		plugins = []request.BotPluginIdInfo{}
+	}
+	r.PluginIdList = &request.BotPluginIdList{IdList: plugins}
+	return r
+}
+
+// WithModel 设置 Bot 使用的模型及参数。
+func (r *CreateRequest) WithModel(model request.BotModelInfoConfig) *CreateRequest {
+	r.ModelInfoConfig = &model
+	return r
+}
+
+// Validate 校验请求参数，包括 space_id 和 name 必填。
+func (r *CreateRequest) Validate() error {
+	if err := request.ValidateRequired("space_id", r.SpaceId); err != nil {
+		return err
+	}
+	return request.ValidateRequired("name", r.Name)
+}
+
+func (r *CreateRequest) Do(ctx context.Context) (*response.DataResponse[CreateData], error) {
+	if !r.skipValidation {
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	body, err := jsoniter.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := new(response.DataResponse[CreateData])
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, createUrl, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add(HeaderContentType, HeaderApplicationJson)
+	req.Header.Add(HeaderAuthorization, fmt.Sprintf("Bearer %s", r.bot.authorization))
+
+	client := http.DefaultClient
+	if r.timeout != 0 {
+		client = &http.Client{Timeout: r.timeout}
+	}
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, &response.HttpErrorResponse{
+			Status:     httpResp.Status,
+			StatusCode: httpResp.StatusCode,
+			Body:       data,
+		}
+	}
+	if err = jsoniter.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// UpdateRequest 更新 Bot 的配置，未设置的字段保持不变。更新后需要重新发布才能在 API 中生效。
+type UpdateRequest struct {
+	bot *Bot
+
+	timeout time.Duration
+	// 是否跳过发送请求前的参数校验。
+	skipValidation bool
+
+	BotId       string `json:"bot_id"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	IconFileId  string `json:"icon_file_id,omitempty"`
+
+	PromptInfo      *request.BotPromptInfo      `json:"prompt_info,omitempty"`
+	OnboardingInfo  *request.BotOnboardingInfo  `json:"onboarding_info,omitempty"`
+	PluginIdList    *request.BotPluginIdList    `json:"plugin_id_list,omitempty"`
+	ModelInfoConfig *request.BotModelInfoConfig `json:"model_info_config,omitempty"`
+	Knowledge       *request.BotKnowledge       `json:"knowledge,omitempty"`
+}
+
+func (r *UpdateRequest) WithTimeout(timeout time.Duration) *UpdateRequest {
+	r.timeout = timeout
+	return r
+}
+
+// WithSkipValidation 设置是否跳过 Do 发送请求前的参数校验。
+func (r *UpdateRequest) WithSkipValidation(skip bool) *UpdateRequest {
+	r.skipValidation = skip
+	return r
+}
+
+func (r *UpdateRequest) WithName(name string) *UpdateRequest {
+	r.Name = name
+	return r
+}
+
+func (r *UpdateRequest) WithDescription(description string) *UpdateRequest {
+	r.Description = description
+	return r
+}
+
+// WithIconFileId 设置 Bot 的头像，fileId 为通过 file 包上传图片得到的文件 ID。
+func (r *UpdateRequest) WithIconFileId(fileId string) *UpdateRequest {
+	r.IconFileId = fileId
+	return r
+}
+
+// WithPrompt 设置 Bot 的人设与回复逻辑。
+func (r *UpdateRequest) WithPrompt(prompt string) *UpdateRequest {
+	r.PromptInfo = &request.BotPromptInfo{Prompt: prompt}
+	return r
+}
+
+// WithOnboarding 设置 Bot 的开场白和预置问题。
+func (r *UpdateRequest) WithOnboarding(prologue string, suggestedQuestions ...string) *UpdateRequest {
+	r.OnboardingInfo = &request.BotOnboardingInfo{Prologue: prologue, SuggestedQuestions: suggestedQuestions}
+	return r
+}
+
+// WithPlugins 设置 Bot 使用的插件工具，会整体替换原有的插件，不传参数时清空所有插件。
+func (r *UpdateRequest) WithPlugins(plugins ...request.BotPluginIdInfo) *UpdateRequest {
+	if plugins == nil {
+		// 以空数组而不是 null 发送，服务端才会清空插件。
+		plugins = []request.BotPluginIdInfo{}
+	}
+	r.PluginIdList = &request.BotPluginIdList{IdList: plugins}
+	return r
+}
+
+// WithModel 设置 Bot 使用的模型及参数。
+func (r *UpdateRequest) WithModel(model request.BotModelInfoConfig) *UpdateRequest {
+	r.ModelInfoConfig = &model
+	return r
+}
+
+// WithKnowledge 设置 Bot 使用的知识库，会整体替换原有的知识库配置。
+func (r *UpdateRequest) WithKnowledge(knowledge request.BotKnowledge) *UpdateRequest {
+	r.Knowledge = &knowledge
+	return r
+}
+
+// Validate 校验请求参数，至少需要修改一项配置。
+func (r *UpdateRequest) Validate() error {
+	if r.Name == "" && r.Description == "" && r.IconFileId == "" && r.PromptInfo == nil && r.OnboardingInfo == nil &&
+		r.PluginIdList == nil && r.ModelInfoConfig == nil && r.Knowledge == nil {
+		return request.NewValidationError("bot", "at least one field to update is required")
+	}
+	return nil
+}
+
+func (r *UpdateRequest) Do(ctx context.Context, botId string) (*response.BaseResponse, error) {
+	if err := request.ValidateRequired("bot_id", botId); err != nil {
+		return nil, err
+	}
+	if !r.skipValidation {
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	r.BotId = botId
+
+	body, err := jsoniter.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := new(response.BaseResponse)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, updateUrl, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add(HeaderContentType, HeaderApplicationJson)
+	req.Header.Add(HeaderAuthorization, fmt.Sprintf("Bearer %s", r.bot.authorization))
+
+	client := http.DefaultClient
+	if r.timeout != 0 {
+		client = &http.Client{Timeout: r.timeout}
+	}
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, &response.HttpErrorResponse{
+			Status:     httpResp.Status,
+			StatusCode: httpResp.StatusCode,
+			Body:       data,
+		}
+	}
+	if err = jsoniter.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// PublishRequest 将 Bot 的最新配置发布到指定渠道，默认发布为 API 服务。
+type PublishRequest struct {
+	bot *Bot
+
+	timeout time.Duration
+
+	BotId        string   `json:"bot_id"`
+	ConnectorIds []string `json:"connector_ids"`
+}
+
+func (r *PublishRequest) WithTimeout(timeout time.Duration) *PublishRequest {
+	r.timeout = timeout
+	return r
+}
+
+// WithConnectorIds 设置发布的渠道，例如 ConnectorIdAPI 和 ConnectorIdWebSDK。
+func (r *PublishRequest) WithConnectorIds(connectorIds ...string) *PublishRequest {
+	r.ConnectorIds = connectorIds
+	return r
+}
+
+func (r *PublishRequest) Do(ctx context.Context, botId string) (*response.DataResponse[PublishData], error) {
+	if err := request.ValidateRequired("bot_id", botId); err != nil {
+		return nil, err
+	}
+	if len(r.ConnectorIds) == 0 {
+		return nil, request.NewValidationError("connector_ids", "is required")
+	}
+	r.BotId = botId
+
+	body, err := jsoniter.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := new(response.DataResponse[PublishData])
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, publishUrl, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add(HeaderContentType, HeaderApplicationJson)
+	req.Header.Add(HeaderAuthorization, fmt.Sprintf("Bearer %s", r.bot.authorization))
+
+	client := http.DefaultClient
+	if r.timeout != 0 {
+		client = &http.Client{Timeout: r.timeout}
+	}
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, &response.HttpErrorResponse{
+			Status:     httpResp.Status,
+			StatusCode: httpResp.StatusCode,
+			Body:       data,
+		}
+	}
+	if err = jsoniter.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// ListPublishedRequest 查询空间中已发布为 API 服务的 Bot 列表。
+type ListPublishedRequest struct {
+	bot *Bot
+
+	timeout time.Duration
+	// 是否跳过发送请求前的参数校验。
+	skipValidation bool
+
+	spaceId   string
+	pageIndex int
+	pageSize  int
+}
+
+func (r *ListPublishedRequest) WithTimeout(timeout time.Duration) *ListPublishedRequest {
+	r.timeout = timeout
+	return r
+}
+
+// WithSkipValidation 设置是否跳过 Do 发送请求前的参数校验。
+func (r *ListPublishedRequest) WithSkipValidation(skip bool) *ListPublishedRequest {
+	r.skipValidation = skip
+	return r
+}
+
+// WithPageIndex 设置页码，从 1 开始，默认为 1。
+func (r *ListPublishedRequest) WithPageIndex(pageIndex int) *ListPublishedRequest {
+	r.pageIndex = pageIndex
+	return r
+}
+
+// WithPageSize 设置每页的数量，默认为 20。
+func (r *ListPublishedRequest) WithPageSize(pageSize int) *ListPublishedRequest {
+	r.pageSize = pageSize
+	return r
+}
+
+// Validate 校验请求参数，包括 space_id 必填以及页码和每页数量的范围。
+func (r *ListPublishedRequest) Validate() error {
+	if err := request.ValidateRequired("space_id", r.spaceId); err != nil {
+		return err
+	}
+	if r.pageIndex < 1 {
+		return request.NewValidationError("page_index", "must be greater than 0")
+	}
+	if r.pageSize < 1 {
+		return request.NewValidationError("page_size", "must be greater than 0")
+	}
+	return nil
+}
+
+func (r *ListPublishedRequest) Do(ctx context.Context) (*response.DataResponse[ListPublishedData], error) {
+	if !r.skipValidation {
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := new(response.DataResponse[ListPublishedData])
+
+	params := url.Values{}
+	params.Add("space_id", r.spaceId)
+	params.Add("page_index", strconv.Itoa(r.pageIndex))
+	params.Add("page_size", strconv.Itoa(r.pageSize))
+
+	u, err := url.Parse(listPublishedUrl)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add(HeaderContentType, HeaderApplicationJson)
+	req.Header.Add(HeaderAuthorization, fmt.Sprintf("Bearer %s", r.bot.authorization))
+
+	client := http.DefaultClient
+	if r.timeout != 0 {
+		client = &http.Client{Timeout: r.timeout}
+	}
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, &response.HttpErrorResponse{
+			Status:     httpResp.Status,
+			StatusCode: httpResp.StatusCode,
+			Body:       data,
+		}
+	}
+	if err = jsoniter.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// Iterator 返回一个从当前页码开始、按需逐页查询的 Bot 迭代器。
+func (r *ListPublishedRequest) Iterator() *pager.Pager[response.SimpleBot] {
+	req := *r
+	return pager.NewNumberPager(req.pageIndex, func(ctx context.Context, pageIndex int) ([]response.SimpleBot, bool, error) {
+		resp, err := req.WithPageIndex(pageIndex).Do(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		if err = resp.Err(); err != nil {
+			return nil, false, err
+		}
+		hasMore := len(resp.Data.SpaceBots) > 0 && pageIndex*req.pageSize < resp.Data.Total
+		return resp.Data.SpaceBots, hasMore, nil
+	})
+}
+
+// All 遍历并返回空间中所有已发布的 Bot。
+func (r *ListPublishedRequest) All(ctx context.Context) ([]response.SimpleBot, error) {
+	return r.Iterator().All(ctx)
+}
+
+// RetrieveRequest 查询 Bot 已发布版本的配置信息。
+type RetrieveRequest struct {
+	bot *Bot
+
+	timeout time.Duration
+}
+
+func (r *RetrieveRequest) WithTimeout(timeout time.Duration) *RetrieveRequest {
+	r.timeout = timeout
+	return r
+}
+
+func (r *RetrieveRequest) Do(ctx context.Context, botId string) (*response.DataResponse[response.Bot], error) {
+	if err := request.ValidateRequired("bot_id", botId); err != nil {
+		return nil, err
+	}
+
+	resp := new(response.DataResponse[response.Bot])
+
+	params := url.Values{}
+	params.Add("bot_id", botId)
+
+	u, err := url.Parse(retrieveUrl)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add(HeaderContentType, HeaderApplicationJson)
+	req.Header.Add(HeaderAuthorization, fmt.Sprintf("Bearer %s", r.bot.authorization))
+
+	client := http.DefaultClient
+	if r.timeout != 0 {
+		client = &http.Client{Timeout: r.timeout}
+	}
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, &response.HttpErrorResponse{
+			Status:     httpResp.Status,
+			StatusCode: httpResp.StatusCode,
+			Body:       data,
+		}
+	}
+	if err = jsoniter.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/chenmingyong0423/go-coze/internal/cozetest"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func requireValidationError(t *testing.T, err error) {
+	var validationErr *request.ValidationError
+	require.True(t, errors.As(err, &validationErr), err)
+}
+
+// newFakeBots 启动一个本地服务，记录每个接口收到的请求体。
+func newFakeBots(t *testing.T, published int) map[string]map[string]any {
+	bodies := make(map[string]map[string]any)
+	record := func(r *http.Request) {
+		body := make(map[string]any)
+		require.NoError(t, jsoniter.NewDecoder(r.Body).Decode(&body))
+		bodies[r.URL.Path] = body
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/bot/create", func(w http.ResponseWriter, r *http.Request) {
+		record(r)
+		cozetest.WriteData(w, CreateData{BotId: "bot-1"})
+	})
+	mux.HandleFunc("/v1/bot/update", func(w http.ResponseWriter, r *http.Request) {
+		record(r)
+		cozetest.WriteJSON(w, response.BaseResponse{})
+	})
+	mux.HandleFunc("/v1/bot/publish", func(w http.ResponseWriter, r *http.Request) {
+		record(r)
+		cozetest.WriteData(w, PublishData{BotId: "bot-1", Version: "2"})
+	})
+	mux.HandleFunc("/v1/space/published_bots_list", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "space", r.URL.Query().Get("space_id"))
+		pageIndex, _ := strconv.Atoi(r.URL.Query().Get("page_index"))
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+		bots := make([]response.SimpleBot, 0)
+		for i := (pageIndex-1)*pageSize + 1; i <= pageIndex*pageSize && i <= published; i++ {
+			bots = append(bots, response.SimpleBot{BotId: fmt.Sprintf("bot-%d", i)})
+		}
+		cozetest.WriteData(w, ListPublishedData{SpaceBots: bots, Total: published})
+	})
+	mux.HandleFunc("/v1/bot/get_online_info", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "bot-1", r.URL.Query().Get("bot_id"))
+		_, _ = w.Write([]byte(`{"code":0,"msg":"","data":{"bot_id":"bot-1","name":"客服","version":"2","bot_mode":0,
+			"prompt_info":{"prompt":"你是客服"},
+			"onboarding_info":{"prologue":"你好","suggested_questions":["退货"]},
+			"plugin_info_list":[{"plugin_id":"p1","name":"天气","api_info_list":[{"api_id":"a1","name":"查询"}]}],
+			"model_info":{"model_id":"m1","model_name":"豆包","temperature":0.7},
+			"knowledge":{"dataset_ids":["d1"],"auto_call":true,"search_strategy":1}}}`))
+	})
+	cozetest.NewServer(t, mux)
+	return bodies
+}
+
+func TestCreateRequest_Do(t *testing.T) {
+	bodies := newFakeBots(t, 0)
+	temperature := 0.5
+	resp, err := NewBot("token").CreateRequest("space").
+		WithName("客服").
+		WithPrompt("你是客服").
+		WithOnboarding("你好", "退货", "换货").
+		WithPlugins(request.BotPluginIdInfo{PluginId: "p1", ApiId: "a1"}).
+		WithModel(request.BotModelInfoConfig{ModelId: "m1", Temperature: &temperature}).
+		Do(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "bot-1", resp.Data.BotId)
+	require.Equal(t, map[string]any{
+		"space_id":          "space",
+		"name":              "客服",
+		"prompt_info":       map[string]any{"prompt": "你是客服"},
+		"onboarding_info":   map[string]any{"prologue": "你好", "suggested_questions": []any{"退货", "换货"}},
+		"plugin_id_list":    map[string]any{"id_list": []any{map[string]any{"plugin_id": "p1", "api_id": "a1"}}},
+		"model_info_config": map[string]any{"model_id": "m1", "temperature": 0.5},
+	}, bodies["/v1/bot/create"])
+
+	_, err = NewBot("token").CreateRequest("space").Do(context.Background())
+	requireValidationError(t, err)
+	_, err = NewBot("token").CreateRequest("").WithName("客服").Do(context.Background())
+	requireValidationError(t, err)
+}
+
+func TestUpdateRequest_Do(t *testing.T) {
+	bodies := newFakeBots(t, 0)
+	resp, err := NewBot("token").UpdateRequest().
+		WithDescription("新的描述").
+		WithKnowledge(request.BotKnowledge{DatasetIds: []string{"d1"}, AutoCall: true}).
+		Do(context.Background(), "bot-1")
+	require.NoError(t, err)
+	require.NoError(t, resp.Err())
+	require.Equal(t, map[string]any{
+		"bot_id":      "bot-1",
+		"description": "新的描述",
+		"knowledge":   map[string]any{"dataset_ids": []any{"d1"}, "auto_call": true, "search_strategy": float64(0)},
+	}, bodies["/v1/bot/update"])
+
+	// 不传参数时以空数组清空插件，而不是发送 null。
+	_, err = NewBot("token").UpdateRequest().WithPlugins().Do(context.Background(), "bot-1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"bot_id":         "bot-1",
+		"plugin_id_list": map[string]any{"id_list": []any{}},
+	}, bodies["/v1/bot/update"])
+
+	_, err = NewBot("token").UpdateRequest().Do(context.Background(), "bot-1")
+	requireValidationError(t, err)
+	_, err = NewBot("token").UpdateRequest().WithName("客服").Do(context.Background(), "")
+	requireValidationError(t, err)
+}
+
+func TestPublishRequest_Do(t *testing.T) {
+	bodies := newFakeBots(t, 0)
+	resp, err := NewBot("token").PublishRequest().Do(context.Background(), "bot-1")
+	require.NoError(t, err)
+	require.Equal(t, "2", resp.Data.Version)
+	require.Equal(t, []any{ConnectorIdAPI}, bodies["/v1/bot/publish"]["connector_ids"])
+
+	_, err = NewBot("token").PublishRequest().WithConnectorIds().Do(context.Background(), "bot-1")
+	requireValidationError(t, err)
+}
+
+func TestListPublishedRequest(t *testing.T) {
+	newFakeBots(t, 45)
+	resp, err := NewBot("token").ListPublishedRequest("space").Do(context.Background())
+	require.NoError(t, err)
+	require.Len(t, resp.Data.SpaceBots, DefaultListPageSize)
+	require.Equal(t, 45, resp.Data.Total)
+
+	bots, err := NewBot("token").ListPublishedRequest("space").WithPageSize(10).All(context.Background())
+	require.NoError(t, err)
+	require.Len(t, bots, 45)
+	require.Equal(t, "bot-45", bots[44].BotId)
+
+	_, err = NewBot("token").ListPublishedRequest("").Do(context.Background())
+	requireValidationError(t, err)
+	_, err = NewBot("token").ListPublishedRequest("space").WithPageIndex(0).Do(context.Background())
+	requireValidationError(t, err)
+}
+
+func TestRetrieveRequest_Do(t *testing.T) {
+	newFakeBots(t, 0)
+	resp, err := NewBot("token").RetrieveRequest().Do(context.Background(), "bot-1")
+	require.NoError(t, err)
+	temperature := 0.7
+	require.Equal(t, response.Bot{
+		BotId:          "bot-1",
+		Name:           "客服",
+		Version:        "2",
+		PromptInfo:     request.BotPromptInfo{Prompt: "你是客服"},
+		OnboardingInfo: request.BotOnboardingInfo{Prologue: "你好", SuggestedQuestions: []string{"退货"}},
+		PluginInfoList: []response.BotPluginInfo{{PluginId: "p1", Name: "天气", ApiInfoList: []response.BotPluginApiInfo{{ApiId: "a1", Name: "查询"}}}},
+		ModelInfo:      response.BotModelInfo{ModelId: "m1", ModelName: "豆包", Temperature: &temperature},
+		Knowledge:      request.BotKnowledge{DatasetIds: []string{"d1"}, AutoCall: true, SearchStrategy: 1},
+	}, resp.Data)
+
+	_, err = NewBot("token").RetrieveRequest().Do(context.Background(), "")
+	requireValidationError(t, err)
+}
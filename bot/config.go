@@ -0,0 +1,316 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/chenmingyong0423/go-coze/internal/yaml"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Config 以声明的方式描述一个已存在的 Bot 的期望配置，用于将 Bot 的配置纳入代码管理。
+// 零值字段（空字符串、nil）表示不管理该字段，保持线上配置不变；空数组表示清空。
+//
+// 配置可以使用 JSON 或 YAML 格式，两种格式的字段名相同，嵌套的 model、knowledge 和 plugins 也使用 JSON 的字段名。
+type Config struct {
+	BotId       string `json:"bot_id" yaml:"bot_id"`
+	Name        string `json:"name,omitempty" yaml:"name,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Prompt      string `json:"prompt,omitempty" yaml:"prompt,omitempty"`
+	// 开场白。
+	Prologue string `json:"prologue,omitempty" yaml:"prologue,omitempty"`
+	// 开场白中的预置问题。
+	SuggestedQuestions []string                    `json:"suggested_questions,omitempty" yaml:"suggested_questions,omitempty"`
+	Model              *request.BotModelInfoConfig `json:"model,omitempty" yaml:"model,omitempty"`
+	Knowledge          *request.BotKnowledge       `json:"knowledge,omitempty" yaml:"knowledge,omitempty"`
+	Plugins            []request.BotPluginIdInfo   `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+	// 发布的渠道，默认发布为 API 服务。
+	ConnectorIds []string `json:"connector_ids,omitempty" yaml:"connector_ids,omitempty"`
+}
+
+// LoadConfig 读取 JSON 格式的 Bot 配置，bot_id 必填。
+func LoadConfig(r io.Reader) (*Config, error) {
+	cfg := new(Config)
+	if err := jsoniter.NewDecoder(r).Decode(cfg); err != nil {
+		return nil, fmt.Errorf("bot: malformed config: %w", err)
+	}
+	if err := request.ValidateRequired("bot_id", cfg.BotId); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadConfigYAML 读取 YAML 格式的 Bot 配置，bot_id 必填。
+// 只支持 YAML 的常用子集，不支持锚点、别名和标签；纯数字的 ID 需要加引号，否则会被解析为数字。
+func LoadConfigYAML(r io.Reader) (*Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	v, err := yaml.Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("bot: malformed config: %w", err)
+	}
+	// 转换为 JSON 后解码，与 JSON 格式的配置使用相同的字段名。
+	if data, err = jsoniter.Marshal(v); err != nil {
+		return nil, fmt.Errorf("bot: malformed config: %w", err)
+	}
+	return LoadConfig(bytes.NewReader(data))
+}
+
+// LoadConfigFile 读取 Bot 配置文件，扩展名为 .yaml 或 .yml 时按 YAML 格式解析，否则按 JSON 格式解析。
+func LoadConfigFile(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return LoadConfigYAML(f)
+	}
+	return LoadConfig(f)
+}
+
+// FieldDiff 表示一个字段的线上值与期望值不同。
+type FieldDiff struct {
+	Field   string
+	Current any
+	Desired any
+}
+
+func (d FieldDiff) String() string {
+	return fmt.Sprintf("~ %s: %s -> %s", d.Field, formatValue(d.Current), formatValue(d.Desired))
+}
+
+func formatValue(v any) string {
+	s, err := jsoniter.MarshalToString(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return s
+}
+
+// Plan 是期望配置与线上配置之间的差异，可在评审后通过 Apply 应用。
+type Plan struct {
+	Config  *Config
+	Current response.Bot
+	Diffs   []FieldDiff
+}
+
+// Empty 返回线上配置是否已与期望配置一致。
+func (p *Plan) Empty() bool {
+	return len(p.Diffs) == 0
+}
+
+// String 以逐行的形式输出每个字段的差异。
+func (p *Plan) String() string {
+	if p.Empty() {
+		return fmt.Sprintf("bot %s: no changes\n", p.Config.BotId)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "bot %s: %d change(s)\n", p.Config.BotId, len(p.Diffs))
+	for _, d := range p.Diffs {
+		b.WriteString(d.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// changed 返回是否有以 prefix 开头的字段存在差异。
+func (p *Plan) changed(prefix string) bool {
+	for _, d := range p.Diffs {
+		if d.Field == prefix || strings.HasPrefix(d.Field, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Plan 查询 Bot 已发布版本的配置，并与期望配置逐字段比较。
+func (b *Bot) Plan(ctx context.Context, cfg *Config) (*Plan, error) {
+	resp, err := b.RetrieveRequest().Do(ctx, cfg.BotId)
+	if err != nil {
+		return nil, err
+	}
+	if err = resp.Err(); err != nil {
+		return nil, err
+	}
+	return Diff(cfg, resp.Data), nil
+}
+
+// Diff 逐字段比较期望配置与线上配置。
+func Diff(cfg *Config, current response.Bot) *Plan {
+	p := &Plan{Config: cfg, Current: current}
+	add := func(field string, managed bool, currentValue, desiredValue any) {
+		if managed && !reflect.DeepEqual(currentValue, desiredValue) {
+			p.Diffs = append(p.Diffs, FieldDiff{Field: field, Current: currentValue, Desired: desiredValue})
+		}
+	}
+
+	add("name", cfg.Name != "", current.Name, cfg.Name)
+	add("description", cfg.Description != "", current.Description, cfg.Description)
+	add("prompt_info.prompt", cfg.Prompt != "", current.PromptInfo.Prompt, cfg.Prompt)
+	add("onboarding_info.prologue", cfg.Prologue != "", current.OnboardingInfo.Prologue, cfg.Prologue)
+	add("onboarding_info.suggested_questions", cfg.SuggestedQuestions != nil,
+		nonNil(current.OnboardingInfo.SuggestedQuestions), nonNil(cfg.SuggestedQuestions))
+
+	if m := cfg.Model; m != nil {
+		cm := current.ModelInfo
+		add("model_info.model_id", m.ModelId != "", cm.ModelId, m.ModelId)
+		add("model_info.temperature", m.Temperature != nil, cm.Temperature, m.Temperature)
+		add("model_info.top_p", m.TopP != nil, cm.TopP, m.TopP)
+		add("model_info.max_tokens", m.MaxTokens != 0, cm.MaxTokens, m.MaxTokens)
+		add("model_info.context_round", m.ContextRound != 0, cm.ContextRound, m.ContextRound)
+		add("model_info.response_format", m.ResponseFormat != 0, cm.ResponseFormat, m.ResponseFormat)
+	}
+
+	if k := cfg.Knowledge; k != nil {
+		ck := current.Knowledge
+		add("knowledge.dataset_ids", true, sortedCopy(ck.DatasetIds), sortedCopy(k.DatasetIds))
+		add("knowledge.auto_call", true, ck.AutoCall, k.AutoCall)
+		add("knowledge.search_strategy", true, ck.SearchStrategy, k.SearchStrategy)
+	}
+
+	if cfg.Plugins != nil {
+		var currentPlugins []string
+		for _, plugin := range current.PluginInfoList {
+			for _, api := range plugin.ApiInfoList {
+				currentPlugins = append(currentPlugins, plugin.PluginId+"/"+api.ApiId)
+			}
+		}
+		var desiredPlugins []string
+		for _, plugin := range cfg.Plugins {
+			desiredPlugins = append(desiredPlugins, plugin.PluginId+"/"+plugin.ApiId)
+		}
+		add("plugins", true, sortedCopy(currentPlugins), sortedCopy(desiredPlugins))
+	}
+	return p
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func sortedCopy(s []string) []string {
+	c := append([]string{}, s...)
+	sort.Strings(c)
+	return c
+}
+
+// Apply 将 Plan 中的差异通过更新接口写入 Bot，并发布到配置中的渠道。
+// 没有差异时不做任何修改，返回 nil。只会更新存在差异的配置项，配置项中未管理的字段沿用线上的值。
+func (b *Bot) Apply(ctx context.Context, p *Plan) (*PublishData, error) {
+	if p.Empty() {
+		return nil, nil
+	}
+	cfg, current := p.Config, p.Current
+
+	update := b.UpdateRequest()
+	if p.changed("name") {
+		update.WithName(cfg.Name)
+	}
+	if p.changed("description") {
+		update.WithDescription(cfg.Description)
+	}
+	if p.changed("prompt_info") {
+		update.WithPrompt(cfg.Prompt)
+	}
+	if p.changed("onboarding_info") {
+		onboarding := current.OnboardingInfo
+		if cfg.Prologue != "" {
+			onboarding.Prologue = cfg.Prologue
+		}
+		if cfg.SuggestedQuestions != nil {
+			onboarding.SuggestedQuestions = cfg.SuggestedQuestions
+		}
+		update.WithOnboarding(onboarding.Prologue, onboarding.SuggestedQuestions...)
+	}
+	if p.changed("model_info") {
+		update.WithModel(mergeModel(current.ModelInfo, *cfg.Model))
+	}
+	if p.changed("knowledge") {
+		update.WithKnowledge(*cfg.Knowledge)
+	}
+	if p.changed("plugins") {
+		update.WithPlugins(cfg.Plugins...)
+	}
+
+	updateResp, err := update.Do(ctx, cfg.BotId)
+	if err != nil {
+		return nil, err
+	}
+	if err = updateResp.Err(); err != nil {
+		return nil, err
+	}
+
+	publish := b.PublishRequest()
+	if len(cfg.ConnectorIds) > 0 {
+		publish.WithConnectorIds(cfg.ConnectorIds...)
+	}
+	publishResp, err := publish.Do(ctx, cfg.BotId)
+	if err != nil {
+		return nil, err
+	}
+	if err = publishResp.Err(); err != nil {
+		return nil, err
+	}
+	return &publishResp.Data, nil
+}
+
+// mergeModel 以线上的模型配置为基础，覆盖期望配置中管理的字段。
+func mergeModel(current response.BotModelInfo, desired request.BotModelInfoConfig) request.BotModelInfoConfig {
+	merged := request.BotModelInfoConfig{
+		ModelId:        current.ModelId,
+		Temperature:    current.Temperature,
+		TopP:           current.TopP,
+		MaxTokens:      current.MaxTokens,
+		ContextRound:   current.ContextRound,
+		ResponseFormat: current.ResponseFormat,
+	}
+	if desired.ModelId != "" {
+		merged.ModelId = desired.ModelId
+	}
+	if desired.Temperature != nil {
+		merged.Temperature = desired.Temperature
+	}
+	if desired.TopP != nil {
+		merged.TopP = desired.TopP
+	}
+	if desired.MaxTokens != 0 {
+		merged.MaxTokens = desired.MaxTokens
+	}
+	if desired.ContextRound != 0 {
+		merged.ContextRound = desired.ContextRound
+	}
+	if desired.ResponseFormat != 0 {
+		merged.ResponseFormat = desired.ResponseFormat
+	}
+	return merged
+}
@@ -0,0 +1,151 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bot
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/stretchr/testify/require"
+)
+
+const desiredConfig = `{
+	"bot_id": "bot-1",
+	"name": "客服",
+	"prompt": "你是一名耐心的客服",
+	"suggested_questions": ["退货", "换货"],
+	"model": {"model_id": "m1", "temperature": 0.3},
+	"plugins": [],
+	"connector_ids": ["1024", "999"]
+}`
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig(strings.NewReader(desiredConfig))
+	require.NoError(t, err)
+	require.Equal(t, "bot-1", cfg.BotId)
+	require.NotNil(t, cfg.Plugins)
+	require.Empty(t, cfg.Plugins)
+	require.Nil(t, cfg.Knowledge)
+
+	_, err = LoadConfig(strings.NewReader(`{"name": "客服"}`))
+	requireValidationError(t, err)
+	_, err = LoadConfig(strings.NewReader(`{`))
+	require.Error(t, err)
+}
+
+const desiredConfigYAML = `# 客服 Bot
+bot_id: "bot-1"
+name: 客服
+prompt: |
+  你是一名耐心的客服
+suggested_questions:
+  - 退货
+  - 换货
+model:
+  model_id: m1
+  temperature: 0.3
+knowledge:
+  dataset_ids: ["d1"]
+  auto_call: true
+plugins: []
+connector_ids: ['1024', '999']
+`
+
+func TestLoadConfigYAML(t *testing.T) {
+	cfg, err := LoadConfigYAML(strings.NewReader(desiredConfigYAML))
+	require.NoError(t, err)
+	jsonCfg, err := LoadConfig(strings.NewReader(desiredConfig))
+	require.NoError(t, err)
+	jsonCfg.Prompt += "\n"
+	jsonCfg.Knowledge = &request.BotKnowledge{DatasetIds: []string{"d1"}, AutoCall: true}
+	require.Equal(t, jsonCfg, cfg)
+	require.NotNil(t, cfg.Plugins)
+
+	_, err = LoadConfigYAML(strings.NewReader("name: 客服\n"))
+	requireValidationError(t, err)
+	_, err = LoadConfigYAML(strings.NewReader("bot_id: [bot-1\n"))
+	require.Error(t, err)
+	// 未加引号的数字 ID 会被解析为数字。
+	_, err = LoadConfigYAML(strings.NewReader("bot_id: 7379462189365198898\n"))
+	require.Error(t, err)
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{"bot.json": desiredConfig, "bot.yaml": desiredConfigYAML, "bot.YML": desiredConfigYAML} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+		cfg, err := LoadConfigFile(path)
+		require.NoError(t, err, name)
+		require.Equal(t, "bot-1", cfg.BotId)
+		require.Equal(t, []string{"退货", "换货"}, cfg.SuggestedQuestions)
+	}
+
+	// YAML 内容使用 .json 扩展名时按 JSON 解析。
+	path := filepath.Join(dir, "yaml.json")
+	require.NoError(t, os.WriteFile(path, []byte(desiredConfigYAML), 0o600))
+	_, err := LoadConfigFile(path)
+	require.Error(t, err)
+}
+
+func TestBot_PlanAndApply(t *testing.T) {
+	bodies := newFakeBots(t, 0)
+	cfg, err := LoadConfig(strings.NewReader(desiredConfig))
+	require.NoError(t, err)
+
+	b := NewBot("token")
+	plan, err := b.Plan(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Equal(t, `bot bot-1: 4 change(s)
+~ prompt_info.prompt: "你是客服" -> "你是一名耐心的客服"
+~ onboarding_info.suggested_questions: ["退货"] -> ["退货","换货"]
+~ model_info.temperature: 0.7 -> 0.3
+~ plugins: ["p1/a1"] -> []
+`, plan.String())
+
+	published, err := b.Apply(context.Background(), plan)
+	require.NoError(t, err)
+	require.Equal(t, "2", published.Version)
+	require.Equal(t, map[string]any{
+		"bot_id":            "bot-1",
+		"prompt_info":       map[string]any{"prompt": "你是一名耐心的客服"},
+		"onboarding_info":   map[string]any{"prologue": "你好", "suggested_questions": []any{"退货", "换货"}},
+		"model_info_config": map[string]any{"model_id": "m1", "temperature": 0.3},
+		"plugin_id_list":    map[string]any{"id_list": []any{}},
+	}, bodies["/v1/bot/update"])
+	require.Equal(t, []any{"1024", "999"}, bodies["/v1/bot/publish"]["connector_ids"])
+}
+
+func TestBot_ApplyNoChanges(t *testing.T) {
+	bodies := newFakeBots(t, 0)
+	b := NewBot("token")
+	plan, err := b.Plan(context.Background(), &Config{
+		BotId:     "bot-1",
+		Name:      "客服",
+		Knowledge: &request.BotKnowledge{DatasetIds: []string{"d1"}, AutoCall: true, SearchStrategy: 1},
+	})
+	require.NoError(t, err)
+	require.True(t, plan.Empty())
+	require.Equal(t, "bot bot-1: no changes\n", plan.String())
+
+	published, err := b.Apply(context.Background(), plan)
+	require.NoError(t, err)
+	require.Nil(t, published)
+	require.Empty(t, bodies)
+}
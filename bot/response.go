@@ -0,0 +1,35 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bot
+
+import "github.com/chenmingyong0423/go-coze/common/response"
+
+type CreateData struct {
+	BotId string `json:"bot_id"`
+}
+
+type PublishData struct {
+	BotId string `json:"bot_id"`
+	// The version of the bot after publishing.
+	// 发布后的版本号。
+	Version string `json:"version"`
+}
+
+type ListPublishedData struct {
+	SpaceBots []response.SimpleBot `json:"space_bots"`
+	// The total number of published bots in the space.
+	// 空间中已发布的 Bot 总数。
+	Total int `json:"total"`
+}
@@ -1,7 +1,6 @@
 package chat
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"fmt"
@@ -12,6 +11,7 @@ import (
 	"time"
 
 	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/chenmingyong0423/go-coze/internal/sse"
 	jsoniter "github.com/json-iterator/go"
 
 	"github.com/chenmingyong0423/go-coze/common/request"
@@ -30,6 +30,9 @@ const (
 	HeaderAuthorization   = "authorization"
 	HeaderContentType     = "Content-Type"
 	HeaderApplicationJson = "application/json"
+
+	// 取消仍在进行中的对话时使用的超时时间。
+	cancelTimeout = 5 * time.Second
 )
 
 type Chat struct {
@@ -80,8 +83,12 @@ type CreateRequest struct {
 	chat    *Chat
 	timeout time.Duration
 
+	// 是否跳过发送请求前的参数校验。
+	skipValidation bool
+
 	// Optional: Indicate which conversation the dialog is taking place in.
 	// 可选的：标识对话发生在哪一次会话中，使用方自行维护此字段。
+	// 发起对话的接口不支持指定 section，对话总是在会话最新的 section 中进行，需要开启新的 section 时请先调用 conversation.ClearRequest。
 	conversationId string
 
 	BotID  string `json:"bot_id"`
@@ -116,6 +123,12 @@ func (r *CreateRequest) WithTimeout(timeout time.Duration) *CreateRequest {
 	return r
 }
 
+// WithSkipValidation 设置是否跳过 Do 和 DoStream 发送请求前的参数校验。
+func (r *CreateRequest) WithSkipValidation(skip bool) *CreateRequest {
+	r.skipValidation = skip
+	return r
+}
+
 func (r *CreateRequest) WithConversationId(conversationId string) *CreateRequest {
 	r.conversationId = conversationId
 	return r
@@ -148,6 +161,11 @@ func (r *CreateRequest) WithExtraParams(extraParams []string) *CreateRequest {
 
 func (r *CreateRequest) Do(ctx context.Context) (*response.DataResponse[*response.Chat], error) {
 	r.Stream = false
+	if !r.skipValidation {
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+	}
 
 	body, err := jsoniter.Marshal(r)
 	if err != nil {
@@ -176,7 +194,7 @@ func (r *CreateRequest) Do(ctx context.Context) (*response.DataResponse[*respons
 
 	client := http.DefaultClient
 	if r.timeout != 0 {
-		client.Timeout = r.timeout
+		client = &http.Client{Timeout: r.timeout}
 	}
 
 	httpResp, err := client.Do(req)
@@ -213,6 +231,13 @@ func (r *CreateRequest) DoStream(ctx context.Context) (<-chan *StreamingResponse
 		defer close(respChan)
 		defer close(errChan)
 
+		if !r.skipValidation {
+			if err := r.Validate(); err != nil {
+				errChan <- err
+				return
+			}
+		}
+
 		body, err := jsoniter.Marshal(r)
 		if err != nil {
 			errChan <- err
@@ -240,7 +265,7 @@ func (r *CreateRequest) DoStream(ctx context.Context) (<-chan *StreamingResponse
 
 		client := http.DefaultClient
 		if r.timeout != 0 {
-			client.Timeout = r.timeout
+			client = &http.Client{Timeout: r.timeout}
 		}
 
 		httpResp, err := client.Do(req)
@@ -260,58 +285,21 @@ func (r *CreateRequest) DoStream(ctx context.Context) (<-chan *StreamingResponse
 			return
 		}
 
-		scanner := bufio.NewScanner(httpResp.Body)
+		reader := sse.NewReader(httpResp.Body)
 
 		sr := &StreamingResponse{}
-		for scanner.Scan() {
-			line := scanner.Text()
-			if strings.HasPrefix(line, "event:") {
-				event := strings.TrimSpace(line[len("event:"):])
-				if event == "[DONE]" {
-					break
-				} else {
-					sr.Event = event
-					continue
-				}
-			} else if strings.HasPrefix(line, "data:") {
-				data := strings.TrimSpace(line[len("data:"):])
-				if data == "conversation.chat.failed" {
-					var errResp response.BaseResponse
-					if err = jsoniter.UnmarshalFromString(data, &errResp); err != nil {
-						errChan <- err
-						return
-					}
-					sr.BaseResponse = errResp
-					newSr := *sr
-					resetStreamResponse(sr)
-					respChan <- &newSr
-					break
-				} else {
-					if strings.Contains(sr.Event, "chat") {
-						var chatResp response.Chat
-						if err = jsoniter.UnmarshalFromString(data, &chatResp); err != nil {
-							errChan <- err
-							return
-						}
-						sr.Chat = &chatResp
-						newSr := *sr
-						resetStreamResponse(sr)
-						respChan <- &newSr
-					} else if strings.Contains(sr.Event, "message") {
-						var messageResp response.Message
-						if err = jsoniter.UnmarshalFromString(data, &messageResp); err != nil {
-							errChan <- err
-							return
-						}
-						sr.Message = &messageResp
-						newSr := *sr
-						resetStreamResponse(sr)
-						respChan <- &newSr
-					}
-				}
-			} else if line != "" {
+		for {
+			ev, err := reader.Next()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				errChan <- err
+				return
+			}
+			if ev.Plain {
 				var resp response.BaseResponse
-				if err = jsoniter.UnmarshalFromString(line, &resp); err != nil {
+				if err = jsoniter.UnmarshalFromString(ev.Data, &resp); err != nil {
 					errChan <- err
 					return
 				}
@@ -321,19 +309,57 @@ func (r *CreateRequest) DoStream(ctx context.Context) (<-chan *StreamingResponse
 				respChan <- &newSr
 				return
 			}
-		}
-
-		if err = scanner.Err(); err != nil {
-			errChan <- err
-			return
+			if ev.Event == "[DONE]" {
+				return
+			}
+			sr.Event = ev.Event
+			if strings.Contains(sr.Event, "chat") {
+				var chatResp response.Chat
+				if err = jsoniter.UnmarshalFromString(ev.Data, &chatResp); err != nil {
+					errChan <- err
+					return
+				}
+				sr.Chat = &chatResp
+				newSr := *sr
+				resetStreamResponse(sr)
+				respChan <- &newSr
+			} else if strings.Contains(sr.Event, "message") {
+				var messageResp response.Message
+				if err = jsoniter.UnmarshalFromString(ev.Data, &messageResp); err != nil {
+					errChan <- err
+					return
+				}
+				sr.Message = &messageResp
+				newSr := *sr
+				resetStreamResponse(sr)
+				respChan <- &newSr
+			}
 		}
 	}()
 
 	return respChan, errChan
 }
 
+// Validate 校验请求参数，包括 bot_id、user_id 等必填字段、消息的角色和内容类型以及附加信息的大小限制。
+func (r *CreateRequest) Validate() error {
+	if err := request.ValidateRequired("bot_id", r.BotID); err != nil {
+		return err
+	}
+	if err := request.ValidateRequired("user_id", r.UserId); err != nil {
+		return err
+	}
+	if err := request.ValidateMessages("additional_messages", r.AdditionalMessages); err != nil {
+		return err
+	}
+	return request.ValidateMetaData("meta_data", r.MetaData)
+}
+
 // Reset 如果你想复用该对象，建议调用该方法重置。
+// 该对象不是并发安全的，如需在多个 goroutine 中复用相同的参数，请使用 CreateRequestTemplate。
 func (r *CreateRequest) Reset() {
+	r.timeout = 0
+	r.skipValidation = false
+	r.conversationId = ""
 	r.Stream = false
 	r.AdditionalMessages = nil
 	r.CustomVariables = nil
@@ -384,7 +410,7 @@ func (r *RetrieveRequest) Do(ctx context.Context, chatId string) (*response.Data
 
 	client := http.DefaultClient
 	if r.timeout != 0 {
-		client.Timeout = r.timeout
+		client = &http.Client{Timeout: r.timeout}
 	}
 
 	httpResp, err := client.Do(req)
@@ -448,7 +474,7 @@ func (r *MessageListRequest) Do(ctx context.Context, chatId string) (*response.D
 
 	client := http.DefaultClient
 	if r.timeout != 0 {
-		client.Timeout = r.timeout
+		client = &http.Client{Timeout: r.timeout}
 	}
 
 	httpResp, err := client.Do(req)
@@ -510,7 +536,7 @@ func (r *CancelRequest) Do(ctx context.Context, chatId string) (*response.DataRe
 
 	client := http.DefaultClient
 	if r.timeout != 0 {
-		client.Timeout = r.timeout
+		client = &http.Client{Timeout: r.timeout}
 	}
 
 	httpResp, err := client.Do(req)
@@ -537,3 +563,46 @@ func (r *CancelRequest) Do(ctx context.Context, chatId string) (*response.DataRe
 
 	return resp, nil
 }
+
+// Wait 每隔 pollInterval 查询一次对话状态，直到对话结束，返回对话结束时的状态。
+// 对话结束前 ctx 被取消或查询失败时会调用 Cancel 取消该对话，避免会话被一直占用，此时返回最后一次查询到的状态和对应的错误。
+func (c *Chat) Wait(ctx context.Context, chat *response.Chat, pollInterval time.Duration) (*response.Chat, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for !chat.IsTerminal() {
+		select {
+		case <-ctx.Done():
+			_ = c.Cancel(chat)
+			return chat, ctx.Err()
+		case <-ticker.C:
+		}
+		resp, err := c.RetrieveRequest(chat.ConversationId).Do(ctx, chat.Id)
+		if err == nil {
+			err = resp.Err()
+		}
+		if err != nil {
+			_ = c.Cancel(chat)
+			if ctx.Err() != nil {
+				return chat, ctx.Err()
+			}
+			return chat, err
+		}
+		chat = resp.Data
+	}
+	return chat, nil
+}
+
+// Cancel 取消进行中的对话，对话已结束时直接返回。
+// 取消使用独立的超时时间，即使调用方的 ctx 已被取消也会执行，适合在放弃对话后释放会话时调用。
+func (c *Chat) Cancel(chat *response.Chat) error {
+	if chat == nil || chat.IsTerminal() {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cancelTimeout)
+	defer cancel()
+	resp, err := c.CancelRequest(chat.ConversationId).Do(ctx, chat.Id)
+	if err != nil {
+		return err
+	}
+	return resp.Err()
+}
@@ -2,6 +2,8 @@ package chat
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -45,10 +47,7 @@ func TestCreateRequest_Do(t *testing.T) {
 			autoSaveHistory:    false,
 			metaData:           nil,
 			extraParams:        nil,
-			wantFunc: func(t *testing.T, resp *response.DataResponse[*response.Chat]) {
-				require.NotNil(t, resp)
-				require.Equal(t, 4006, resp.Code)
-			},
+			wantErrorFunc:      requireValidationError,
 		},
 		{
 			name:           "invalid request params for userId",
@@ -65,10 +64,7 @@ func TestCreateRequest_Do(t *testing.T) {
 			autoSaveHistory: false,
 			metaData:        nil,
 			extraParams:     nil,
-			wantFunc: func(t *testing.T, resp *response.DataResponse[*response.Chat]) {
-				require.NotNil(t, resp)
-				require.Equal(t, 4000, resp.Code)
-			},
+			wantErrorFunc:   requireValidationError,
 		},
 		{
 			name:           "invalid request params for token",
@@ -134,11 +130,79 @@ func TestCreateRequest_Do(t *testing.T) {
 	}
 }
 
+func TestCreateRequest_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		req     *CreateRequest
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "empty botID",
+			req:     NewChat("token", "user", "").ChatRequest(),
+			wantErr: requireValidationError,
+		},
+		{
+			name:    "empty userId",
+			req:     NewChat("token", "", "bot").ChatRequest(),
+			wantErr: requireValidationError,
+		},
+		{
+			name: "invalid role",
+			req: NewChat("token", "user", "bot").ChatRequest().
+				AddMessages(request.NewEnterMessageBuilder().Role("system").Content("你好").ContentType("text").Build()),
+			wantErr: requireValidationError,
+		},
+		{
+			name: "invalid content type",
+			req: NewChat("token", "user", "bot").ChatRequest().
+				AddMessages(request.NewEnterMessageBuilder().Role("user").Content("你好").ContentType("markdown").Build()),
+			wantErr: requireValidationError,
+		},
+		{
+			name: "invalid object_string content",
+			req: NewChat("token", "user", "bot").ChatRequest().
+				AddMessages(request.NewEnterMessageBuilder().Role("user").ObjectStringContent(request.ObjectString{Type: "image"}).Build()),
+			wantErr: requireValidationError,
+		},
+		{
+			name:    "too many meta data pairs",
+			req:     NewChat("token", "user", "bot").ChatRequest().WithMetaData(metaDataOfSize(request.MaxMetaDataPairs + 1)),
+			wantErr: requireValidationError,
+		},
+		{
+			name: "success",
+			req: NewChat("token", "user", "bot").ChatRequest().
+				AddMessages(request.NewEnterMessageBuilder().Role("user").
+					ObjectStringContent(request.NewObjectStringsBuilder().Text("这是什么？").Image("123", "").Build()...).Build()).
+				WithMetaData(metaDataOfSize(request.MaxMetaDataPairs)),
+			wantErr: require.NoError,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.wantErr(t, tc.req.Validate())
+		})
+	}
+}
+
+func requireValidationError(t require.TestingT, err error, i ...interface{}) {
+	var validationErr *request.ValidationError
+	require.True(t, errors.As(err, &validationErr), "unexpected error: %v", err)
+}
+
+func metaDataOfSize(size int) map[string]any {
+	metaData := make(map[string]any, size)
+	for i := 0; i < size; i++ {
+		metaData[fmt.Sprintf("key%d", i)] = "value"
+	}
+	return metaData
+}
+
 func TestCreateRequest_DoStream(t *testing.T) {
 	{
 		chat := NewChat("", "", "")
 
-		respChan, errChan := chat.ChatRequest().WithConversationId("").
+		respChan, errChan := chat.ChatRequest().WithSkipValidation(true).WithConversationId("").
 			AddMessages(request.NewEnterMessageBuilder().Role("user").Content("你好").ContentType("text").Build()).
 			WithCustomVariables(nil).
 			WithAutoSaveHistory(false).
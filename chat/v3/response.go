@@ -4,6 +4,18 @@ import (
 	"github.com/chenmingyong0423/go-coze/common/response"
 )
 
+const (
+	EventChatCreated        = "conversation.chat.created"
+	EventChatInProgress     = "conversation.chat.in_progress"
+	EventChatCompleted      = "conversation.chat.completed"
+	EventChatFailed         = "conversation.chat.failed"
+	EventChatRequiresAction = "conversation.chat.requires_action"
+	EventMessageDelta       = "conversation.message.delta"
+	EventMessageCompleted   = "conversation.message.completed"
+	EventError              = "error"
+	EventDone               = "done"
+)
+
 type StreamingResponse struct {
 	response.BaseResponse
 	Event   string
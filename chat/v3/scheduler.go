@@ -0,0 +1,248 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chat
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/chenmingyong0423/go-coze/common/response"
+)
+
+const defaultSchedulerPollInterval = time.Second
+
+// ErrQueueFull 表示会话的等待队列已满。
+var ErrQueueFull = errors.New("chat: conversation queue is full")
+
+// Scheduler 按会话 ID 串行执行对话请求，保证同一个会话中同时最多只有一个进行中的对话。
+// 同一个会话的请求按提交顺序执行，未指定会话 ID 的请求会直接执行。Scheduler 可以在多个 goroutine 中安全使用。
+type Scheduler struct {
+	mu     sync.Mutex
+	queues map[string]*conversationQueue
+
+	maxQueueDepth int
+	pollInterval  time.Duration
+	onWait        func(conversationId string, wait time.Duration)
+}
+
+type conversationQueue struct {
+	active  bool
+	waiters []chan struct{}
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		queues:       make(map[string]*conversationQueue),
+		pollInterval: defaultSchedulerPollInterval,
+	}
+}
+
+// WithMaxQueueDepth 设置每个会话最多等待的请求数（不包含正在执行的请求），超过时返回 ErrQueueFull，0 表示不限制。
+func (s *Scheduler) WithMaxQueueDepth(maxQueueDepth int) *Scheduler {
+	s.maxQueueDepth = maxQueueDepth
+	return s
+}
+
+// WithPollInterval 设置非流式对话轮询对话状态的时间间隔，默认为 1 秒。
+func (s *Scheduler) WithPollInterval(pollInterval time.Duration) *Scheduler {
+	s.pollInterval = pollInterval
+	return s
+}
+
+// WithWaitObserver 设置排队等待时间的回调，每个请求开始执行或放弃等待时都会调用，可用于上报监控指标。
+func (s *Scheduler) WithWaitObserver(onWait func(conversationId string, wait time.Duration)) *Scheduler {
+	s.onWait = onWait
+	return s
+}
+
+// QueueLen 返回会话中正在等待的请求数。
+func (s *Scheduler) QueueLen(conversationId string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if q, ok := s.queues[conversationId]; ok {
+		return len(q.waiters)
+	}
+	return 0
+}
+
+// ScheduledResult 表示经 Scheduler 执行的非流式对话的结果。
+type ScheduledResult struct {
+	// 创建对话的响应。
+	Response *response.DataResponse[*response.Chat]
+	// 对话结束时的状态，创建对话失败时为 nil。
+	Chat *response.Chat
+	// 在队列中等待的时间。
+	WaitTime time.Duration
+}
+
+// Do 排队执行非流式对话，并等待对话结束后才让同一会话中的下一个请求开始执行。
+// ctx 在排队期间被取消时请求会被移出队列；在对话进行中被取消或查询对话状态失败时，会先取消该对话再让下一个请求开始。
+func (s *Scheduler) Do(ctx context.Context, r *CreateRequest) (*ScheduledResult, error) {
+	wait, err := s.acquire(ctx, r.conversationId)
+	if err != nil {
+		return nil, err
+	}
+	defer s.release(r.conversationId)
+
+	result := &ScheduledResult{WaitTime: wait}
+	resp, err := r.Do(ctx)
+	if err != nil {
+		return result, err
+	}
+	result.Response = resp
+	if err = resp.Err(); err != nil {
+		return result, err
+	}
+
+	c, err := r.chat.Wait(ctx, resp.Data, s.pollInterval)
+	result.Chat = c
+	return result, err
+}
+
+// DoStream 排队执行流式对话，流结束后才让同一会话中的下一个请求开始执行。
+// 调用方必须读取两个 channel 直到它们都被关闭，或者在取消 ctx 后停止读取；流在对话结束前中断时会先取消该对话。
+func (s *Scheduler) DoStream(ctx context.Context, r *CreateRequest) (<-chan *StreamingResponse, <-chan error) {
+	respChan := make(chan *StreamingResponse)
+	errChan := make(chan error)
+
+	go func() {
+		defer close(respChan)
+		defer close(errChan)
+
+		// ctx 被取消后不再等待调用方读取，避免一直占用会话的队列。
+		sendErr := func(err error) {
+			select {
+			case errChan <- err:
+			case <-ctx.Done():
+			}
+		}
+
+		if _, err := s.acquire(ctx, r.conversationId); err != nil {
+			sendErr(err)
+			return
+		}
+		defer s.release(r.conversationId)
+
+		// 记录流中最新的对话状态，流异常结束时对话可能仍在进行，需要先取消再让下一个请求开始。
+		var current *response.Chat
+		defer func() { _ = r.chat.Cancel(current) }()
+
+		// 即使调用方已停止读取，也要读完上游的 channel，让上游的 goroutine 能够退出。
+		upstreamResp, upstreamErr := r.DoStream(ctx)
+		for upstreamResp != nil || upstreamErr != nil {
+			select {
+			case resp, ok := <-upstreamResp:
+				if !ok {
+					upstreamResp = nil
+					continue
+				}
+				if resp.Chat != nil {
+					current = resp.Chat
+				}
+				select {
+				case respChan <- resp:
+				case <-ctx.Done():
+				}
+			case err, ok := <-upstreamErr:
+				if !ok {
+					upstreamErr = nil
+					continue
+				}
+				sendErr(err)
+			}
+		}
+	}()
+	return respChan, errChan
+}
+
+// acquire 等待轮到该会话的请求执行，返回等待的时间。
+func (s *Scheduler) acquire(ctx context.Context, conversationId string) (time.Duration, error) {
+	if conversationId == "" {
+		return 0, nil
+	}
+	start := time.Now()
+
+	s.mu.Lock()
+	q, ok := s.queues[conversationId]
+	if !ok {
+		q = &conversationQueue{}
+		s.queues[conversationId] = q
+	}
+	if !q.active && len(q.waiters) == 0 {
+		q.active = true
+		s.mu.Unlock()
+		s.observe(conversationId, 0)
+		return 0, nil
+	}
+	if s.maxQueueDepth > 0 && len(q.waiters) >= s.maxQueueDepth {
+		s.mu.Unlock()
+		return 0, ErrQueueFull
+	}
+	ready := make(chan struct{})
+	q.waiters = append(q.waiters, ready)
+	s.mu.Unlock()
+
+	select {
+	case <-ready:
+		wait := time.Since(start)
+		s.observe(conversationId, wait)
+		return wait, nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		removed := false
+		for i, waiter := range q.waiters {
+			if waiter == ready {
+				q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+				removed = true
+				break
+			}
+		}
+		s.mu.Unlock()
+		if !removed {
+			// 取消的同时已经轮到该请求，需要把执行权交给下一个请求。
+			s.release(conversationId)
+		}
+		s.observe(conversationId, time.Since(start))
+		return 0, ctx.Err()
+	}
+}
+
+// release 结束当前请求，并唤醒队列中的下一个请求。
+func (s *Scheduler) release(conversationId string) {
+	if conversationId == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q, ok := s.queues[conversationId]
+	if !ok {
+		return
+	}
+	if len(q.waiters) > 0 {
+		next := q.waiters[0]
+		q.waiters = q.waiters[1:]
+		close(next)
+		return
+	}
+	delete(s.queues, conversationId)
+}
+
+func (s *Scheduler) observe(conversationId string, wait time.Duration) {
+	if s.onWait != nil {
+		s.onWait(conversationId, wait)
+	}
+}
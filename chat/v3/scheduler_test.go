@@ -0,0 +1,286 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/chenmingyong0423/go-coze/internal/cozetest"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChatServer 模拟 Coze 的对话接口，同一个会话中有进行中的对话时再次发起对话会返回 4016。
+type fakeChatServer struct {
+	mu       sync.Mutex
+	chats    int
+	active   map[string]bool
+	polls    map[string]int
+	canceled []string
+	// 流式对话在发送 created 事件后保持进行中的时间。
+	streamHold time.Duration
+	// 为 true 时查询对话状态的接口返回错误。
+	failRetrieve bool
+}
+
+func newFakeChatServer(t *testing.T) *fakeChatServer {
+	f := &fakeChatServer{active: map[string]bool{}, polls: map[string]int{}, streamHold: 5 * time.Millisecond}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v3/chat", func(w http.ResponseWriter, r *http.Request) {
+		var req CreateRequest
+		_ = jsoniter.NewDecoder(r.Body).Decode(&req)
+		conversationId := r.URL.Query().Get("conversation_id")
+		f.mu.Lock()
+		if f.active[conversationId] {
+			f.mu.Unlock()
+			cozetest.WriteJSON(w, response.BaseResponse{Code: 4016, Msg: "conversation occupied"})
+			return
+		}
+		f.chats++
+		c := &response.Chat{Id: fmt.Sprintf("chat-%d", f.chats), ConversationId: conversationId, Status: response.ChatStatusInProgress}
+		f.active[conversationId] = true
+		f.mu.Unlock()
+
+		if !req.Stream {
+			cozetest.WriteData(w, c)
+			return
+		}
+		data, _ := jsoniter.MarshalToString(c)
+		_, _ = fmt.Fprintf(w, "event:%s\ndata:%s\n\n", EventChatCreated, data)
+		w.(http.Flusher).Flush()
+		select {
+		case <-time.After(f.streamHold):
+		case <-r.Context().Done():
+			return
+		}
+		f.mu.Lock()
+		delete(f.active, conversationId)
+		f.mu.Unlock()
+		c.Status = response.ChatStatusCompleted
+		data, _ = jsoniter.MarshalToString(c)
+		_, _ = fmt.Fprintf(w, "event:%s\ndata:%s\n\n", EventChatCompleted, data)
+	})
+	mux.HandleFunc("/v3/chat/retrieve", func(w http.ResponseWriter, r *http.Request) {
+		conversationId, chatId := r.URL.Query().Get("conversation_id"), r.URL.Query().Get("chat_id")
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if f.failRetrieve {
+			cozetest.WriteJSON(w, response.BaseResponse{Code: 5000, Msg: "internal error"})
+			return
+		}
+		f.polls[chatId]++
+		c := &response.Chat{Id: chatId, ConversationId: conversationId, Status: response.ChatStatusInProgress}
+		if f.polls[chatId] >= 2 {
+			c.Status = response.ChatStatusCompleted
+			delete(f.active, conversationId)
+		}
+		cozetest.WriteData(w, c)
+	})
+	mux.HandleFunc("/v3/chat/cancel", func(w http.ResponseWriter, r *http.Request) {
+		conversationId, chatId := r.URL.Query().Get("conversation_id"), r.URL.Query().Get("chat_id")
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.canceled = append(f.canceled, chatId)
+		delete(f.active, conversationId)
+		cozetest.WriteData(w, &response.Chat{Id: chatId, ConversationId: conversationId, Status: response.ChatStatusCanceled})
+	})
+	cozetest.NewServer(t, mux)
+	return f
+}
+
+func newScheduledRequest(conversationId string) *CreateRequest {
+	return NewChat("token", "user", "bot").ChatRequest().WithConversationId(conversationId).WithAutoSaveHistory(true).
+		AddMessages(request.NewEnterMessageBuilder().Role("user").Content("你好").ContentType("text").Build())
+}
+
+func TestScheduler_Do(t *testing.T) {
+	f := newFakeChatServer(t)
+	var waits int
+	var mu sync.Mutex
+	s := NewScheduler().WithPollInterval(time.Millisecond).WithWaitObserver(func(conversationId string, wait time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		waits++
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := s.Do(context.Background(), newScheduledRequest(fmt.Sprintf("conversation-%d", i%2)))
+			require.NoError(t, err)
+			require.Equal(t, 0, result.Response.Code)
+			require.Equal(t, response.ChatStatusCompleted, result.Chat.Status)
+		}(i)
+	}
+	wg.Wait()
+	require.Equal(t, 8, f.chats)
+	require.Equal(t, 8, waits)
+	require.Zero(t, s.QueueLen("conversation-0"))
+}
+
+func TestScheduler_DoStream(t *testing.T) {
+	newFakeChatServer(t)
+	s := NewScheduler()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			respChan, errChan := s.DoStream(context.Background(), newScheduledRequest("conversation").WithAutoSaveHistory(false))
+			var events []string
+			for respChan != nil || errChan != nil {
+				select {
+				case resp, ok := <-respChan:
+					if !ok {
+						respChan = nil
+						continue
+					}
+					require.Zero(t, resp.Code)
+					events = append(events, resp.Event)
+				case err, ok := <-errChan:
+					if !ok {
+						errChan = nil
+						continue
+					}
+					require.NoError(t, err)
+				}
+			}
+			require.Equal(t, []string{EventChatCreated, EventChatCompleted}, events)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestScheduler_DoApiError(t *testing.T) {
+	f := newFakeChatServer(t)
+	f.active["busy"] = true
+	s := NewScheduler().WithPollInterval(time.Millisecond)
+
+	result, err := s.Do(context.Background(), newScheduledRequest("busy"))
+	var apiErr *response.ApiError
+	require.True(t, errors.As(err, &apiErr), err)
+	require.Equal(t, 4016, apiErr.Code)
+	require.Equal(t, 4016, result.Response.Code)
+	require.Nil(t, result.Chat)
+	require.Empty(t, s.queues)
+}
+
+func TestScheduler_DoStreamAbandoned(t *testing.T) {
+	f := newFakeChatServer(t)
+	f.streamHold = time.Minute
+	s := NewScheduler().WithPollInterval(time.Millisecond)
+
+	// 读取第一个事件后取消 ctx 并停止读取，会话的队列不能因此被一直占用。
+	ctx, cancel := context.WithCancel(context.Background())
+	respChan, _ := s.DoStream(ctx, newScheduledRequest("conversation").WithAutoSaveHistory(false))
+	resp := <-respChan
+	require.Equal(t, EventChatCreated, resp.Event)
+	cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.Do(context.Background(), newScheduledRequest("conversation"))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("conversation queue is still held by the abandoned stream")
+	}
+	// 被放弃的对话在下一个请求开始前已被取消。
+	require.Equal(t, []string{"chat-1"}, f.canceled)
+}
+
+func TestScheduler_QueueFullAndCancel(t *testing.T) {
+	s := NewScheduler().WithMaxQueueDepth(1)
+	_, err := s.acquire(context.Background(), "conversation")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	queued := make(chan error)
+	go func() {
+		_, err := s.acquire(ctx, "conversation")
+		queued <- err
+	}()
+	waitForQueueLen(t, s, "conversation", 1)
+
+	_, err = s.acquire(context.Background(), "conversation")
+	require.Equal(t, ErrQueueFull, err)
+
+	cancel()
+	require.Equal(t, context.Canceled, <-queued)
+	require.Zero(t, s.QueueLen("conversation"))
+
+	next := make(chan time.Duration)
+	go func() {
+		wait, err := s.acquire(context.Background(), "conversation")
+		require.NoError(t, err)
+		next <- wait
+	}()
+	waitForQueueLen(t, s, "conversation", 1)
+	time.Sleep(5 * time.Millisecond)
+	s.release("conversation")
+	require.True(t, <-next >= 5*time.Millisecond)
+	s.release("conversation")
+	require.Empty(t, s.queues)
+}
+
+func TestScheduler_CancelRunningChat(t *testing.T) {
+	f := newFakeChatServer(t)
+	s := NewScheduler().WithPollInterval(time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	result, err := s.Do(ctx, newScheduledRequest("conversation"))
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.Equal(t, "chat-1", result.Chat.Id)
+	require.Equal(t, []string{"chat-1"}, f.canceled)
+	require.Empty(t, s.queues)
+}
+
+func TestScheduler_CancelOnPollError(t *testing.T) {
+	f := newFakeChatServer(t)
+	f.failRetrieve = true
+	s := NewScheduler().WithPollInterval(time.Millisecond)
+
+	result, err := s.Do(context.Background(), newScheduledRequest("conversation"))
+	var apiErr *response.ApiError
+	require.True(t, errors.As(err, &apiErr), err)
+	require.Equal(t, "chat-1", result.Chat.Id)
+	require.Equal(t, []string{"chat-1"}, f.canceled)
+	require.Empty(t, s.queues)
+}
+
+func waitForQueueLen(t *testing.T, s *Scheduler, conversationId string, want int) {
+	deadline := time.Now().Add(time.Second)
+	for s.QueueLen(conversationId) != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("queue length of %s did not reach %d", conversationId, want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chat
+
+import (
+	"time"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+)
+
+// CreateRequestTemplate 是一个不可变的对话请求模板，可以在多个 goroutine 之间共享。
+// 所有 WithXxx 方法都会返回一个新的模板，不会修改原模板；Request 方法会生成一个独立的 CreateRequest，
+// 其中的 map 和 slice 均为深拷贝，修改生成的请求不会影响模板及其他请求。
+type CreateRequestTemplate struct {
+	chat    *Chat
+	timeout time.Duration
+
+	additionalMessages []request.EnterMessage
+	customVariables    map[string]any
+	autoSaveHistory    bool
+	metaData           map[string]any
+	extraParams        []string
+}
+
+// Template 创建一个绑定当前 Bot 和用户的对话请求模板。
+func (c *Chat) Template() *CreateRequestTemplate {
+	return &CreateRequestTemplate{chat: c}
+}
+
+func (t *CreateRequestTemplate) clone() *CreateRequestTemplate {
+	return &CreateRequestTemplate{
+		chat:               t.chat,
+		timeout:            t.timeout,
+		additionalMessages: copyMessages(t.additionalMessages),
+		customVariables:    copyMap(t.customVariables),
+		autoSaveHistory:    t.autoSaveHistory,
+		metaData:           copyMap(t.metaData),
+		extraParams:        copyStrings(t.extraParams),
+	}
+}
+
+func (t *CreateRequestTemplate) WithTimeout(timeout time.Duration) *CreateRequestTemplate {
+	nt := t.clone()
+	nt.timeout = timeout
+	return nt
+}
+
+// WithMessages 设置每次请求都会携带的上下文消息，例如固定的开场消息。
+func (t *CreateRequestTemplate) WithMessages(messages ...request.EnterMessage) *CreateRequestTemplate {
+	nt := t.clone()
+	nt.additionalMessages = copyMessages(messages)
+	return nt
+}
+
+func (t *CreateRequestTemplate) WithCustomVariables(customVariables map[string]any) *CreateRequestTemplate {
+	nt := t.clone()
+	nt.customVariables = copyMap(customVariables)
+	return nt
+}
+
+func (t *CreateRequestTemplate) WithAutoSaveHistory(autoSaveHistory bool) *CreateRequestTemplate {
+	nt := t.clone()
+	nt.autoSaveHistory = autoSaveHistory
+	return nt
+}
+
+func (t *CreateRequestTemplate) WithMetaData(metaData map[string]any) *CreateRequestTemplate {
+	nt := t.clone()
+	nt.metaData = copyMap(metaData)
+	return nt
+}
+
+func (t *CreateRequestTemplate) WithExtraParams(extraParams []string) *CreateRequestTemplate {
+	nt := t.clone()
+	nt.extraParams = copyStrings(extraParams)
+	return nt
+}
+
+// Request 根据模板生成一个独立的对话请求。
+func (t *CreateRequestTemplate) Request() *CreateRequest {
+	return &CreateRequest{
+		chat:               t.chat,
+		timeout:            t.timeout,
+		BotID:              t.chat.botID,
+		UserId:             t.chat.userId,
+		AdditionalMessages: copyMessages(t.additionalMessages),
+		CustomVariables:    copyMap(t.customVariables),
+		AutoSaveHistory:    t.autoSaveHistory,
+		MetaData:           copyMap(t.metaData),
+		ExtraParams:        copyStrings(t.extraParams),
+	}
+}
+
+func copyMessages(messages []request.EnterMessage) []request.EnterMessage {
+	if messages == nil {
+		return nil
+	}
+	cp := make([]request.EnterMessage, len(messages))
+	for i, message := range messages {
+		message.MetaData = copyMap(message.MetaData)
+		cp[i] = message
+	}
+	return cp
+}
+
+func copyStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	cp := make([]string, len(s))
+	copy(cp, s)
+	return cp
+}
+
+func copyMap(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	cp := make(map[string]any, len(m))
+	for k, v := range m {
+		cp[k] = copyValue(v)
+	}
+	return cp
+}
+
+func copyValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		return copyMap(val)
+	case map[string]string:
+		cp := make(map[string]string, len(val))
+		for k, s := range val {
+			cp[k] = s
+		}
+		return cp
+	case []any:
+		cp := make([]any, len(val))
+		for i, item := range val {
+			cp[i] = copyValue(item)
+		}
+		return cp
+	case []string:
+		return copyStrings(val)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chat
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateRequestTemplate_Request(t *testing.T) {
+	base := NewChat("token", "user", "bot").Template().
+		WithTimeout(time.Second).
+		WithCustomVariables(map[string]any{"name": "coze", "nested": map[string]any{"k": "v"}}).
+		WithMetaData(map[string]any{"tenant": "a"}).
+		WithExtraParams([]string{"latitude"})
+
+	derived := base.WithMetaData(map[string]any{"tenant": "b"})
+	require.Equal(t, "a", base.Request().MetaData["tenant"])
+	require.Equal(t, "b", derived.Request().MetaData["tenant"])
+
+	r1 := base.Request()
+	r2 := base.Request()
+	require.Equal(t, "bot", r1.BotID)
+	require.Equal(t, "user", r1.UserId)
+	require.Equal(t, time.Second, r1.timeout)
+
+	r1.CustomVariables["name"] = "changed"
+	r1.CustomVariables["nested"].(map[string]any)["k"] = "changed"
+	r1.ExtraParams[0] = "changed"
+	r1.AddMessages(request.NewEnterMessageBuilder().Role("user").Content("你好").ContentType("text").Build())
+
+	require.Equal(t, "coze", r2.CustomVariables["name"])
+	require.Equal(t, "v", r2.CustomVariables["nested"].(map[string]any)["k"])
+	require.Equal(t, "latitude", r2.ExtraParams[0])
+	require.Empty(t, r2.AdditionalMessages)
+	require.Equal(t, "coze", base.Request().CustomVariables["name"])
+}
+
+func TestCreateRequestTemplate_Concurrent(t *testing.T) {
+	tpl := NewChat("token", "user", "bot").Template().
+		WithCustomVariables(map[string]any{"name": "coze"}).
+		WithMessages(request.NewEnterMessageBuilder().Role("assistant").Content("欢迎").ContentType("text").
+			MetaData(map[string]any{"pinned": "true"}).Build())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r := tpl.Request().WithConversationId(fmt.Sprintf("conversation-%d", i)).
+				AddMessages(request.NewEnterMessageBuilder().Role("user").Content(fmt.Sprint(i)).ContentType("text").Build())
+			r.CustomVariables["name"] = i
+			r.AdditionalMessages[0].MetaData["pinned"] = "false"
+			require.Len(t, r.AdditionalMessages, 2)
+			require.NoError(t, r.Validate())
+		}(i)
+	}
+	wg.Wait()
+
+	r := tpl.Request()
+	require.Equal(t, "coze", r.CustomVariables["name"])
+	require.Equal(t, "true", r.AdditionalMessages[0].MetaData["pinned"])
+}
+
+func TestCreateRequest_Reset(t *testing.T) {
+	r := NewChat("token", "user", "bot").ChatRequest().
+		WithTimeout(time.Second).WithConversationId("123").WithSkipValidation(true).
+		WithMetaData(map[string]any{"k": "v"})
+	r.Reset()
+	require.Zero(t, r.timeout)
+	require.Empty(t, r.conversationId)
+	require.False(t, r.skipValidation)
+	require.Nil(t, r.MetaData)
+	require.Equal(t, "bot", r.BotID)
+}
@@ -0,0 +1,76 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// cozebot 根据声明式的配置文件管理 Bot 的配置。
+//
+//	COZE_TOKEN=xxx cozebot plan -f bot.json    输出线上配置与配置文件之间逐字段的差异
+//	COZE_TOKEN=xxx cozebot apply -f bot.json   应用差异并发布 Bot
+//
+// 配置文件的格式见 bot.Config，支持 JSON 和 YAML，扩展名为 .yaml 或 .yml 的文件按 YAML 格式解析。
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/chenmingyong0423/go-coze/bot"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "cozebot:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 || (args[0] != "plan" && args[0] != "apply") {
+		return fmt.Errorf("usage: cozebot plan|apply -f <config.json|config.yaml>")
+	}
+	command := args[0]
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	path := fs.String("f", "bot.json", "path of the bot config file")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	token := os.Getenv("COZE_TOKEN")
+	if token == "" {
+		return fmt.Errorf("COZE_TOKEN is required")
+	}
+	cfg, err := bot.LoadConfigFile(*path)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	b := bot.NewBot(token)
+	plan, err := b.Plan(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Print(plan.String())
+	if command == "plan" || plan.Empty() {
+		return nil
+	}
+
+	published, err := b.Apply(ctx, plan)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("bot %s published, version %s\n", published.BotId, published.Version)
+	return nil
+}
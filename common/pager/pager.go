@@ -0,0 +1,170 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pager 为 Coze 的各类列表接口提供统一的自动翻页迭代器。
+// Coze 的列表接口有两种翻页方式：基于游标（before_id / after_id / has_more）和基于页码（page_num / page_size）。
+// 两种方式都通过 Pager 遍历：
+//
+//	p := message.NewMessage(token, conversationId).ListRequest().Iterator(message.PageForward)
+//	for p.Next(ctx) {
+//		fmt.Println(p.Item().Content)
+//	}
+//	if err := p.Err(); err != nil {
+//		// 处理错误
+//	}
+package pager
+
+import (
+	"context"
+	"errors"
+)
+
+// CursorFetcher 查询游标 cursor 对应的一页数据，返回这一页的数据、下一页的游标以及是否还有更多数据。
+type CursorFetcher[T any] func(ctx context.Context, cursor string) (items []T, nextCursor string, hasMore bool, err error)
+
+// NumberFetcher 查询第 pageNum 页的数据，返回这一页的数据以及是否还有更多数据。
+type NumberFetcher[T any] func(ctx context.Context, pageNum int) (items []T, hasMore bool, err error)
+
+type page[T any] struct {
+	items   []T
+	hasMore bool
+	err     error
+}
+
+// Pager 是按需逐页查询的迭代器，只有在当前页遍历完后才会查询下一页，可以随时停止遍历。
+// 开启预取后，会在返回当前页的同时在后台查询下一页。Pager 不是并发安全的。
+type Pager[T any] struct {
+	// fetch 查询下一页并推进游标或页码，同一时刻最多只有一个调用在进行。
+	fetch func(ctx context.Context) page[T]
+
+	prefetch bool
+	// 后台预取的结果，未在预取时为 nil。
+	pending chan page[T]
+
+	items   []T
+	index   int
+	hasMore bool
+	err     error
+}
+
+// NewCursorPager 创建基于游标翻页的迭代器，cursor 为第一页的游标，可以为空。
+// 下一页的游标为空或与当前游标相同时停止翻页，避免重复查询同一页。
+func NewCursorPager[T any](cursor string, fetcher CursorFetcher[T]) *Pager[T] {
+	return newPager(func(ctx context.Context) page[T] {
+		items, next, hasMore, err := fetcher(ctx, cursor)
+		if err != nil {
+			return page[T]{err: err}
+		}
+		hasMore = hasMore && next != "" && next != cursor
+		cursor = next
+		return page[T]{items: items, hasMore: hasMore}
+	})
+}
+
+// NewNumberPager 创建基于页码翻页的迭代器，pageNum 为第一页的页码。
+// 某一页没有数据时停止翻页。
+func NewNumberPager[T any](pageNum int, fetcher NumberFetcher[T]) *Pager[T] {
+	return newPager(func(ctx context.Context) page[T] {
+		items, hasMore, err := fetcher(ctx, pageNum)
+		if err != nil {
+			return page[T]{err: err}
+		}
+		pageNum++
+		return page[T]{items: items, hasMore: hasMore && len(items) > 0}
+	})
+}
+
+func newPager[T any](fetch func(ctx context.Context) page[T]) *Pager[T] {
+	return &Pager[T]{fetch: fetch, hasMore: true}
+}
+
+// WithPrefetch 设置是否在后台预取下一页。预取可以减少遍历时的等待，但提前结束遍历时可能会多查询一页。
+func (p *Pager[T]) WithPrefetch(prefetch bool) *Pager[T] {
+	p.prefetch = prefetch
+	return p
+}
+
+// Next 移动到下一项，没有更多数据或发生错误时返回 false。
+func (p *Pager[T]) Next(ctx context.Context) bool {
+	if p.err != nil {
+		return false
+	}
+	for p.index+1 >= len(p.items) {
+		if !p.hasMore {
+			return false
+		}
+		next := p.nextPage(ctx)
+		if next.err != nil {
+			p.err = next.err
+			return false
+		}
+		p.items = next.items
+		p.index = -1
+		p.hasMore = next.hasMore
+		if p.prefetch && p.hasMore {
+			p.startPrefetch(ctx)
+		}
+	}
+	p.index++
+	return true
+}
+
+// nextPage 返回预取的结果，没有预取时同步查询。
+func (p *Pager[T]) nextPage(ctx context.Context) page[T] {
+	if p.pending == nil {
+		return p.fetch(ctx)
+	}
+	var next page[T]
+	select {
+	case next = <-p.pending:
+	case <-ctx.Done():
+		// 后台查询仍在进行，等待其结束后再丢弃，保证 fetch 不会被并发调用。
+		go func(pending chan page[T]) { <-pending }(p.pending)
+		p.pending = nil
+		return page[T]{err: ctx.Err()}
+	}
+	p.pending = nil
+	if next.err != nil && ctx.Err() == nil && (errors.Is(next.err, context.Canceled) || errors.Is(next.err, context.DeadlineExceeded)) {
+		// 预取使用的是上一次调用 Next 时的 ctx，它可能已经结束；查询失败时游标不会推进，可以直接重试。
+		return p.fetch(ctx)
+	}
+	return next
+}
+
+func (p *Pager[T]) startPrefetch(ctx context.Context) {
+	pending := make(chan page[T], 1)
+	p.pending = pending
+	go func() {
+		pending <- p.fetch(ctx)
+	}()
+}
+
+// Item 返回当前项，仅在 Next 返回 true 后有效。
+func (p *Pager[T]) Item() T {
+	return p.items[p.index]
+}
+
+// Err 返回遍历过程中发生的错误。
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+// All 遍历并返回剩余的所有数据。
+func (p *Pager[T]) All(ctx context.Context) ([]T, error) {
+	var items []T
+	for p.Next(ctx) {
+		items = append(items, p.Item())
+	}
+	return items, p.Err()
+}
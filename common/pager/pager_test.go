@@ -0,0 +1,160 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pager
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// numbers 返回第 pageNum 页的数据，每页 size 个数字，共 total 个数字。
+func numbers(pageNum, size, total int) ([]int, bool) {
+	var items []int
+	for i := (pageNum - 1) * size; i < pageNum*size && i < total; i++ {
+		items = append(items, i)
+	}
+	return items, pageNum*size < total
+}
+
+func TestNumberPager(t *testing.T) {
+	var fetched []int
+	p := NewNumberPager(1, func(ctx context.Context, pageNum int) ([]int, bool, error) {
+		fetched = append(fetched, pageNum)
+		items, hasMore := numbers(pageNum, 3, 10)
+		return items, hasMore, nil
+	})
+	items, err := p.All(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, items)
+	require.Equal(t, []int{1, 2, 3, 4}, fetched)
+	require.False(t, p.Next(context.Background()))
+}
+
+func TestNumberPager_EmptyPage(t *testing.T) {
+	calls := 0
+	p := NewNumberPager(1, func(ctx context.Context, pageNum int) ([]int, bool, error) {
+		calls++
+		return nil, true, nil
+	})
+	require.False(t, p.Next(context.Background()))
+	require.NoError(t, p.Err())
+	require.Equal(t, 1, calls)
+}
+
+func TestCursorPager(t *testing.T) {
+	var cursors []string
+	p := NewCursorPager("", func(ctx context.Context, cursor string) ([]string, string, bool, error) {
+		cursors = append(cursors, cursor)
+		start := 0
+		if cursor != "" {
+			start, _ = strconv.Atoi(cursor)
+		}
+		var items []string
+		for i := start; i < start+2 && i < 5; i++ {
+			items = append(items, strconv.Itoa(i))
+		}
+		return items, strconv.Itoa(start + 2), start+2 < 5, nil
+	})
+	items, err := p.All(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"0", "1", "2", "3", "4"}, items)
+	require.Equal(t, []string{"", "2", "4"}, cursors)
+}
+
+func TestCursorPager_StuckCursor(t *testing.T) {
+	calls := 0
+	p := NewCursorPager("a", func(ctx context.Context, cursor string) ([]int, string, bool, error) {
+		calls++
+		return []int{calls}, "a", true, nil
+	})
+	items, err := p.All(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, items)
+}
+
+func TestPager_Error(t *testing.T) {
+	errFetch := errors.New("fetch failed")
+	p := NewNumberPager(1, func(ctx context.Context, pageNum int) ([]int, bool, error) {
+		if pageNum == 2 {
+			return nil, false, errFetch
+		}
+		items, hasMore := numbers(pageNum, 2, 10)
+		return items, hasMore, nil
+	})
+	items, err := p.All(context.Background())
+	require.Equal(t, errFetch, err)
+	require.Equal(t, []int{0, 1}, items)
+	require.False(t, p.Next(context.Background()))
+}
+
+func TestPager_Prefetch(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		fetched []int
+	)
+	release := make(chan struct{})
+	p := NewNumberPager(1, func(ctx context.Context, pageNum int) ([]int, bool, error) {
+		if pageNum == 2 {
+			<-release
+		}
+		mu.Lock()
+		fetched = append(fetched, pageNum)
+		mu.Unlock()
+		items, hasMore := numbers(pageNum, 2, 4)
+		return items, hasMore, nil
+	}).WithPrefetch(true)
+
+	require.True(t, p.Next(context.Background()))
+	require.Equal(t, 0, p.Item())
+	require.True(t, p.Next(context.Background()))
+	// 第二页正在后台查询，遍历第一页时不会被阻塞。
+	mu.Lock()
+	require.Equal(t, []int{1}, fetched)
+	mu.Unlock()
+
+	close(release)
+	items, err := p.All(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 3}, items)
+	require.Equal(t, []int{1, 2}, fetched)
+}
+
+func TestPager_PrefetchWithExpiredContext(t *testing.T) {
+	p := NewNumberPager(1, func(ctx context.Context, pageNum int) ([]int, bool, error) {
+		if _, ok := ctx.Deadline(); ok && pageNum == 2 {
+			<-ctx.Done()
+			return nil, false, ctx.Err()
+		}
+		items, hasMore := numbers(pageNum, 2, 4)
+		return items, hasMore, nil
+	}).WithPrefetch(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	require.True(t, p.Next(ctx))
+	require.True(t, p.Next(ctx))
+	<-ctx.Done()
+	cancel()
+
+	// 预取时使用的 ctx 已经结束，使用新的 ctx 时会重新查询。
+	items, err := p.All(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 3}, items)
+}
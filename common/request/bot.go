@@ -0,0 +1,76 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package request
+
+type BotPromptInfo struct {
+	// The persona and reply logic of the bot.
+	// Bot 的人设与回复逻辑。
+	Prompt string `json:"prompt"`
+}
+
+type BotOnboardingInfo struct {
+	// The opening statement of the bot.
+	// Bot 的开场白。
+	Prologue string `json:"prologue"`
+	// The suggested questions shown with the opening statement.
+	// 开场白中的预置问题。
+	SuggestedQuestions []string `json:"suggested_questions"`
+}
+
+type BotKnowledge struct {
+	// The IDs of the knowledge bases used by the bot.
+	// Bot 关联的知识库 ID。
+	DatasetIds []string `json:"dataset_ids"`
+	// Whether the bot calls the knowledge bases automatically.
+	// 是否自动调用知识库。
+	AutoCall bool `json:"auto_call"`
+	// The search strategy: 0 semantic search, 1 hybrid search, 20 full-text search.
+	// 搜索策略：0 语义搜索，1 混合搜索，20 全文搜索。
+	SearchStrategy int `json:"search_strategy"`
+}
+
+type BotPluginIdInfo struct {
+	// The ID of the plugin.
+	// 插件 ID。
+	PluginId string `json:"plugin_id"`
+	// The ID of the tool in the plugin.
+	// 插件中工具的 ID。
+	ApiId string `json:"api_id"`
+}
+
+type BotPluginIdList struct {
+	IdList []BotPluginIdInfo `json:"id_list"`
+}
+
+type BotModelInfoConfig struct {
+	// The ID of the model.
+	// 模型 ID。
+	ModelId string `json:"model_id"`
+	// The sampling temperature, nil means the default value of the model.
+	// 生成随机性，为 nil 时使用模型的默认值。
+	Temperature *float64 `json:"temperature,omitempty"`
+	// The nucleus sampling probability, nil means the default value of the model.
+	// Top P，为 nil 时使用模型的默认值。
+	TopP *float64 `json:"top_p,omitempty"`
+	// The maximum number of tokens in a reply.
+	// 最大回复长度。
+	MaxTokens int `json:"max_tokens,omitempty"`
+	// The number of conversation rounds carried as context.
+	// 携带上下文轮数。
+	ContextRound int `json:"context_round,omitempty"`
+	// The output format: 0 text, 1 markdown, 2 JSON.
+	// 输出格式：0 文本，1 Markdown，2 JSON。
+	ResponseFormat int `json:"response_format,omitempty"`
+}
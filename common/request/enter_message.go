@@ -14,6 +14,18 @@
 
 package request
 
+import (
+	"fmt"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	ContentTypeText         = "text"
+	ContentTypeObjectString = "object_string"
+	ContentTypeCard         = "card"
+)
+
 type EnterMessage struct {
 	// The role who returns the message.
 	// 发送这条消息的实体。
@@ -33,6 +45,40 @@ type EnterMessage struct {
 	MetaData map[string]any `json:"meta_data,omitempty"`
 }
 
+// ValidateContent 校验消息内容，content_type 为 object_string 时校验多模态消息内容的合法性。
+func (m EnterMessage) ValidateContent() error {
+	if m.ContentType == ContentTypeObjectString {
+		return ValidateObjectStringContent(m.Content)
+	}
+	return nil
+}
+
+// Validate 校验消息的角色、内容类型、内容及附加信息。card 只会出现在 Bot 返回的消息中，不能作为输入消息的内容类型。
+func (m EnterMessage) Validate() error {
+	if err := ValidateRole("role", m.Role); err != nil {
+		return err
+	}
+	if m.ContentType != "" {
+		if err := ValidateContentType("content_type", m.ContentType, ContentTypeText, ContentTypeObjectString); err != nil {
+			return err
+		}
+	}
+	if err := m.ValidateContent(); err != nil {
+		return NewValidationError("content", err.Error())
+	}
+	return ValidateMetaData("meta_data", m.MetaData)
+}
+
+// ValidateMessages 校验消息列表，field 为消息列表对应的请求参数名。
+func ValidateMessages(field string, messages []EnterMessage) error {
+	for i, message := range messages {
+		if err := message.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", field, i, err)
+		}
+	}
+	return nil
+}
+
 type EnterMessageBuilder struct {
 	enterMessage EnterMessage
 }
@@ -61,6 +107,15 @@ func (b *EnterMessageBuilder) ContentType(contentType string) *EnterMessageBuild
 	return b
 }
 
+// ObjectStringContent 设置多模态消息内容，并将 content_type 设置为 object_string。
+// 内容的合法性会在发送请求前校验。
+func (b *EnterMessageBuilder) ObjectStringContent(objectStrings ...ObjectString) *EnterMessageBuilder {
+	content, _ := jsoniter.MarshalToString(objectStrings)
+	b.enterMessage.Content = content
+	b.enterMessage.ContentType = ContentTypeObjectString
+	return b
+}
+
 func (b *EnterMessageBuilder) MetaData(metaData map[string]any) *EnterMessageBuilder {
 	b.enterMessage.MetaData = metaData
 	return b
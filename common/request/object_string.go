@@ -14,6 +14,22 @@
 
 package request
 
+import (
+	"errors"
+	"fmt"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	ObjectStringTypeText  = "text"
+	ObjectStringTypeFile  = "file"
+	ObjectStringTypeImage = "image"
+	ObjectStringTypeAudio = "audio"
+)
+
+var ErrEmptyObjectStrings = errors.New("object_string content must contain at least one part")
+
 type ObjectString struct {
 	// The content type of the multimodal message.
 	// 多模态消息内容类型。
@@ -65,3 +81,82 @@ func (b *ObjectStringBuilder) FileUrl(fileUrl string) *ObjectStringBuilder {
 func (b *ObjectStringBuilder) Build() ObjectString {
 	return b.objectString
 }
+
+// Validate 校验多模态消息内容：text 类型必须指定 text，file、image、audio 类型必须指定 file_id 或 file_url。
+func (o ObjectString) Validate() error {
+	switch o.Type {
+	case ObjectStringTypeText:
+		if o.Text == "" {
+			return errors.New("object_string: text is required when type is text")
+		}
+	case ObjectStringTypeFile, ObjectStringTypeImage, ObjectStringTypeAudio:
+		if o.FileId == "" && o.FileUrl == "" {
+			return fmt.Errorf("object_string: file_id or file_url is required when type is %s", o.Type)
+		}
+	default:
+		return fmt.Errorf("object_string: unsupported type %q", o.Type)
+	}
+	return nil
+}
+
+// MarshalObjectStrings 校验并将多模态消息内容序列化为 Coze API 所需的 JSON 数组。
+func MarshalObjectStrings(objectStrings ...ObjectString) (string, error) {
+	if len(objectStrings) == 0 {
+		return "", ErrEmptyObjectStrings
+	}
+	for i, objectString := range objectStrings {
+		if err := objectString.Validate(); err != nil {
+			return "", fmt.Errorf("part %d: %w", i, err)
+		}
+	}
+	return jsoniter.MarshalToString(objectStrings)
+}
+
+// ValidateObjectStringContent 校验 content_type 为 object_string 时的消息内容。
+func ValidateObjectStringContent(content string) error {
+	var objectStrings []ObjectString
+	if err := jsoniter.UnmarshalFromString(content, &objectStrings); err != nil {
+		return fmt.Errorf("object_string: content must be a JSON array: %w", err)
+	}
+	_, err := MarshalObjectStrings(objectStrings...)
+	return err
+}
+
+// ObjectStringsBuilder 用于构建由多个部分（文本、图片、文件、音频）组成的多模态消息内容。
+type ObjectStringsBuilder struct {
+	objectStrings []ObjectString
+}
+
+func NewObjectStringsBuilder() *ObjectStringsBuilder {
+	return &ObjectStringsBuilder{}
+}
+
+func (b *ObjectStringsBuilder) Add(objectStrings ...ObjectString) *ObjectStringsBuilder {
+	b.objectStrings = append(b.objectStrings, objectStrings...)
+	return b
+}
+
+func (b *ObjectStringsBuilder) Text(text string) *ObjectStringsBuilder {
+	return b.Add(ObjectString{Type: ObjectStringTypeText, Text: text})
+}
+
+func (b *ObjectStringsBuilder) File(fileId, fileUrl string) *ObjectStringsBuilder {
+	return b.Add(ObjectString{Type: ObjectStringTypeFile, FileId: fileId, FileUrl: fileUrl})
+}
+
+func (b *ObjectStringsBuilder) Image(fileId, fileUrl string) *ObjectStringsBuilder {
+	return b.Add(ObjectString{Type: ObjectStringTypeImage, FileId: fileId, FileUrl: fileUrl})
+}
+
+func (b *ObjectStringsBuilder) Audio(fileId, fileUrl string) *ObjectStringsBuilder {
+	return b.Add(ObjectString{Type: ObjectStringTypeAudio, FileId: fileId, FileUrl: fileUrl})
+}
+
+func (b *ObjectStringsBuilder) Build() []ObjectString {
+	return b.objectStrings
+}
+
+// Content 校验并返回序列化后的消息内容，可直接作为 content_type 为 object_string 的 content 使用。
+func (b *ObjectStringsBuilder) Content() (string, error) {
+	return MarshalObjectStrings(b.objectStrings...)
+}
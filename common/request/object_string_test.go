@@ -0,0 +1,115 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package request
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalObjectStrings(t *testing.T) {
+	testCases := []struct {
+		name          string
+		objectStrings []ObjectString
+		want          string
+		wantErr       require.ErrorAssertionFunc
+	}{
+		{
+			name:    "empty",
+			wantErr: require.Error,
+		},
+		{
+			name:          "text without text",
+			objectStrings: []ObjectString{{Type: ObjectStringTypeText}},
+			wantErr:       require.Error,
+		},
+		{
+			name:          "image without file_id and file_url",
+			objectStrings: []ObjectString{{Type: ObjectStringTypeImage}},
+			wantErr:       require.Error,
+		},
+		{
+			name:          "unsupported type",
+			objectStrings: []ObjectString{{Type: "video", FileId: "1"}},
+			wantErr:       require.Error,
+		},
+		{
+			name:          "text and image",
+			objectStrings: NewObjectStringsBuilder().Text("这是什么？").Image("", "https://example.com/a.png").Build(),
+			want:          `[{"type":"text","text":"这是什么？"},{"type":"image","file_url":"https://example.com/a.png"}]`,
+			wantErr:       require.NoError,
+		},
+		{
+			name:          "file and audio",
+			objectStrings: NewObjectStringsBuilder().File("123", "").Audio("456", "").Build(),
+			want:          `[{"type":"file","file_id":"123"},{"type":"audio","file_id":"456"}]`,
+			wantErr:       require.NoError,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := MarshalObjectStrings(tc.objectStrings...)
+			tc.wantErr(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestEnterMessage_ValidateContent(t *testing.T) {
+	valid := NewEnterMessageBuilder().Role("user").
+		ObjectStringContent(NewObjectStringsBuilder().Text("你好").File("123", "").Build()...).Build()
+	require.Equal(t, ContentTypeObjectString, valid.ContentType)
+	require.NoError(t, valid.ValidateContent())
+
+	invalid := NewEnterMessageBuilder().Role("user").ObjectStringContent(ObjectString{Type: ObjectStringTypeFile}).Build()
+	require.Error(t, invalid.ValidateContent())
+
+	empty := NewEnterMessageBuilder().Role("user").ObjectStringContent().Build()
+	require.Error(t, empty.ValidateContent())
+
+	malformed := NewEnterMessageBuilder().Role("user").Content("{").ContentType(ContentTypeObjectString).Build()
+	require.Error(t, malformed.ValidateContent())
+
+	text := NewEnterMessageBuilder().Role("user").Content("你好").ContentType(ContentTypeText).Build()
+	require.NoError(t, text.ValidateContent())
+}
+
+func TestEnterMessage_Validate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		contentType string
+		content     string
+		wantErr     bool
+	}{
+		{name: "default content type", content: "你好"},
+		{name: "text", contentType: ContentTypeText, content: "你好"},
+		{name: "object_string", contentType: ContentTypeObjectString, content: `[{"type":"text","text":"你好"}]`},
+		{name: "card is output only", contentType: ContentTypeCard, content: `{"card_type":3}`, wantErr: true},
+		{name: "unknown", contentType: "image", content: "你好", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := NewEnterMessageBuilder().Role(RoleUser).Content(tc.content).ContentType(tc.contentType).Build().Validate()
+			if tc.wantErr {
+				var validationErr *ValidationError
+				require.True(t, errors.As(err, &validationErr), err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package request
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+
+	// MaxMetaDataPairs 附加信息最多包含的键值对数量。
+	MaxMetaDataPairs = 16
+	// MaxMetaDataKeyLength 附加信息中键的最大长度。
+	MaxMetaDataKeyLength = 64
+	// MaxMetaDataValueLength 附加信息中值的最大长度。
+	MaxMetaDataValueLength = 512
+)
+
+// ValidationError 表示请求参数未通过客户端校验，请求不会被发送。
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid request param %s: %s", e.Field, e.Reason)
+}
+
+func NewValidationError(field, reason string) *ValidationError {
+	return &ValidationError{Field: field, Reason: reason}
+}
+
+// ValidateRequired 校验必填字段。
+func ValidateRequired(field, value string) error {
+	if value == "" {
+		return NewValidationError(field, "is required")
+	}
+	return nil
+}
+
+// ValidateRole 校验消息发送者角色，仅支持 user 和 assistant。
+func ValidateRole(field, role string) error {
+	switch role {
+	case RoleUser, RoleAssistant:
+		return nil
+	case "":
+		return NewValidationError(field, "is required")
+	default:
+		return NewValidationError(field, fmt.Sprintf("unsupported role %q", role))
+	}
+}
+
+// ValidateContentType 校验消息内容类型是否在允许的范围内。
+func ValidateContentType(field, contentType string, allowed ...string) error {
+	for _, a := range allowed {
+		if contentType == a {
+			return nil
+		}
+	}
+	return NewValidationError(field, fmt.Sprintf("unsupported content type %q, allowed: %v", contentType, allowed))
+}
+
+// ValidateMetaData 校验附加信息：最多 16 个键值对，键的长度不超过 64 个字符，值的长度不超过 512 个字符。
+func ValidateMetaData(field string, metaData map[string]any) error {
+	if len(metaData) > MaxMetaDataPairs {
+		return NewValidationError(field, fmt.Sprintf("at most %d key-value pairs are allowed, got %d", MaxMetaDataPairs, len(metaData)))
+	}
+	for k, v := range metaData {
+		if utf8.RuneCountInString(k) > MaxMetaDataKeyLength {
+			return NewValidationError(field, fmt.Sprintf("key %q exceeds %d characters", k, MaxMetaDataKeyLength))
+		}
+		if utf8.RuneCountInString(fmt.Sprint(v)) > MaxMetaDataValueLength {
+			return NewValidationError(field, fmt.Sprintf("value of key %q exceeds %d characters", k, MaxMetaDataValueLength))
+		}
+	}
+	return nil
+}
@@ -26,6 +26,24 @@ type BaseResponse struct {
 	Msg string `json:"msg"`
 }
 
+// Err 在 code 不为 0 时返回 *ApiError，否则返回 nil。
+func (b BaseResponse) Err() error {
+	if b.Code == 0 {
+		return nil
+	}
+	return &ApiError{Code: b.Code, Msg: b.Msg}
+}
+
+// ApiError 表示 Coze API 返回的业务错误，即 code 不为 0 的响应。
+type ApiError struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+func (e *ApiError) Error() string {
+	return fmt.Sprintf("coze api error: code: %d, msg: %s", e.Code, e.Msg)
+}
+
 type DataResponse[T any] struct {
 	BaseResponse
 	Data T `json:"data"`
@@ -0,0 +1,77 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package response
+
+import "github.com/chenmingyong0423/go-coze/common/request"
+
+// Bot 是 Bot 已发布版本的配置信息。
+type Bot struct {
+	BotId       string `json:"bot_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	IconUrl     string `json:"icon_url"`
+	// The creation time of the bot, in the format of a 10-digit Unix timestamp in seconds.
+	// 创建时间，格式为 10 位的 Unix 时间戳，单位为秒。
+	CreateTime int64 `json:"create_time"`
+	// The update time of the bot, in the format of a 10-digit Unix timestamp in seconds.
+	// 更新时间，格式为 10 位的 Unix 时间戳，单位为秒。
+	UpdateTime int64 `json:"update_time"`
+	// The latest published version of the bot.
+	// Bot 最新发布的版本号。
+	Version string `json:"version"`
+	// The mode of the bot: 0 single agent, 1 multi agent, 2 single agent with workflow.
+	// Bot 模式：0 单 Agent，1 多 Agent，2 单 Agent 对话流模式。
+	BotMode        int                       `json:"bot_mode"`
+	PromptInfo     request.BotPromptInfo     `json:"prompt_info"`
+	OnboardingInfo request.BotOnboardingInfo `json:"onboarding_info"`
+	PluginInfoList []BotPluginInfo           `json:"plugin_info_list"`
+	ModelInfo      BotModelInfo              `json:"model_info"`
+	Knowledge      request.BotKnowledge      `json:"knowledge"`
+}
+
+type BotPluginInfo struct {
+	PluginId    string             `json:"plugin_id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	IconUrl     string             `json:"icon_url"`
+	ApiInfoList []BotPluginApiInfo `json:"api_info_list"`
+}
+
+type BotPluginApiInfo struct {
+	ApiId       string `json:"api_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type BotModelInfo struct {
+	ModelId        string   `json:"model_id"`
+	ModelName      string   `json:"model_name"`
+	Temperature    *float64 `json:"temperature,omitempty"`
+	TopP           *float64 `json:"top_p,omitempty"`
+	MaxTokens      int      `json:"max_tokens,omitempty"`
+	ContextRound   int      `json:"context_round,omitempty"`
+	ResponseFormat int      `json:"response_format,omitempty"`
+}
+
+// SimpleBot 是空间中已发布的 Bot 的简要信息。
+type SimpleBot struct {
+	BotId       string `json:"bot_id"`
+	BotName     string `json:"bot_name"`
+	Description string `json:"description"`
+	IconUrl     string `json:"icon_url"`
+	// The last publish time, a Unix timestamp in seconds encoded as a string.
+	// 最近一次发布的时间，字符串格式的 Unix 时间戳，单位为秒。
+	PublishTime string `json:"publish_time"`
+}
@@ -14,10 +14,20 @@
 
 package response
 
+const (
+	ChatStatusCreated        = "created"
+	ChatStatusInProgress     = "in_progress"
+	ChatStatusCompleted      = "completed"
+	ChatStatusFailed         = "failed"
+	ChatStatusRequiresAction = "requires_action"
+	ChatStatusCanceled       = "canceled"
+)
+
 type Chat struct {
 	Id             string            `json:"id"`
 	ConversationId string            `json:"conversation_id"`
 	BotId          string            `json:"bot_id"`
+	SectionId      string            `json:"section_id,omitempty"`
 	CreatedAt      int64             `json:"created_at,omitempty"`
 	CompletedAt    int64             `json:"completed_at,omitempty"`
 	FailedAt       int64             `json:"failed_at,omitempty"`
@@ -28,6 +38,16 @@ type Chat struct {
 	Usage          Usage             `json:"usage,omitempty"`
 }
 
+// IsTerminal 判断对话是否已结束，结束后的对话不会再有状态变化。
+func (c *Chat) IsTerminal() bool {
+	switch c.Status {
+	case ChatStatusCompleted, ChatStatusFailed, ChatStatusRequiresAction, ChatStatusCanceled:
+		return true
+	default:
+		return false
+	}
+}
+
 type LastError struct {
 	Code int    `json:"code"`
 	Msg  string `json:"msg"`
@@ -18,4 +18,13 @@ type Conversation struct {
 	Id        string         `json:"id"`
 	CreatedAt int64          `json:"created_at"`
 	MetaData  map[string]any `json:"meta_data"`
+	// The ID of the latest section of the conversation.
+	// 会话中最新的 section ID，清除会话上下文后会变化。
+	LastSectionId string `json:"last_section_id,omitempty"`
+}
+
+// Section 表示会话中的一段上下文，清除会话上下文时会开启新的 section。
+type Section struct {
+	Id             string `json:"id"`
+	ConversationId string `json:"conversation_id"`
 }
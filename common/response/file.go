@@ -0,0 +1,75 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package response
+
+import (
+	"mime"
+	"path/filepath"
+	"strings"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+)
+
+type File struct {
+	// The ID of the uploaded file.
+	// 已上传的文件 ID。
+	Id string `json:"id"`
+	// The total byte size of the file.
+	// 文件的总字节数。
+	Bytes int64 `json:"bytes"`
+	// The upload time of the file, in the format of a 10-digit Unix timestamp in seconds.
+	// 文件的上传时间，格式为 10 位的 Unix 时间戳，单位为秒。
+	CreatedAt int64 `json:"created_at"`
+	// The name of the file.
+	// 文件名称。
+	FileName string `json:"file_name"`
+}
+
+// Go 内置的 MIME 类型表不包含常见的音频格式，系统中也不一定存在 mime.types 文件，因此补充常见的扩展名。
+var mediaTypes = map[string]string{
+	".mp3":  "audio/mpeg",
+	".wav":  "audio/wav",
+	".m4a":  "audio/mp4",
+	".aac":  "audio/aac",
+	".ogg":  "audio/ogg",
+	".flac": "audio/flac",
+	".amr":  "audio/amr",
+	".bmp":  "image/bmp",
+	".tif":  "image/tiff",
+	".tiff": "image/tiff",
+	".heic": "image/heic",
+}
+
+// MediaType 返回文件名对应的 MIME 类型，无法识别时返回空字符串。
+func MediaType(fileName string) string {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if mediaType, ok := mediaTypes[ext]; ok {
+		return mediaType
+	}
+	return mime.TypeByExtension(ext)
+}
+
+// ObjectString 返回引用该文件的多模态消息内容，类型根据文件扩展名推断：图片为 image，音频为 audio，其余为 file。
+func (f File) ObjectString() request.ObjectString {
+	typ := request.ObjectStringTypeFile
+	mediaType := MediaType(f.FileName)
+	switch {
+	case strings.HasPrefix(mediaType, "image/"):
+		typ = request.ObjectStringTypeImage
+	case strings.HasPrefix(mediaType, "audio/"):
+		typ = request.ObjectStringTypeAudio
+	}
+	return request.ObjectString{Type: typ, FileId: f.Id}
+}
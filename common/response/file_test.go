@@ -0,0 +1,43 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package response
+
+import (
+	"testing"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile_ObjectString(t *testing.T) {
+	testCases := []struct {
+		fileName string
+		want     string
+	}{
+		{fileName: "a.PNG", want: request.ObjectStringTypeImage},
+		{fileName: "a.jpeg", want: request.ObjectStringTypeImage},
+		{fileName: "a.mp3", want: request.ObjectStringTypeAudio},
+		{fileName: "a.wav", want: request.ObjectStringTypeAudio},
+		{fileName: "a.pdf", want: request.ObjectStringTypeFile},
+		{fileName: "noext", want: request.ObjectStringTypeFile},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.fileName, func(t *testing.T) {
+			got := File{Id: "file", FileName: tc.fileName}.ObjectString()
+			require.Equal(t, request.ObjectString{Type: tc.want, FileId: "file"}, got)
+			require.NoError(t, got.Validate())
+		})
+	}
+}
@@ -14,6 +14,14 @@
 
 package response
 
+import (
+	"errors"
+	"fmt"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+	jsoniter "github.com/json-iterator/go"
+)
+
 type Message struct {
 	Id             string         `json:"id"`
 	ConversationId string         `json:"conversation_id"`
@@ -26,4 +34,82 @@ type Message struct {
 	CreateTime     int64          `json:"create_time"`
 	UpdateTime     int64          `json:"update_time"`
 	Type           string         `json:"type"`
+	SectionId      string         `json:"section_id,omitempty"`
+}
+
+const (
+	MessageTypeQuestion     = "question"
+	MessageTypeAnswer       = "answer"
+	MessageTypeFunctionCall = "function_call"
+	MessageTypeToolOutput   = "tool_output"
+	MessageTypeToolResponse = "tool_response"
+	MessageTypeFollowUp     = "follow_up"
+	MessageTypeVerbose      = "verbose"
+)
+
+var (
+	ErrNotObjectStringContent = errors.New("message content type is not object_string")
+	ErrNotCardContent         = errors.New("message content type is not card")
+)
+
+// ObjectStrings 解析 content_type 为 object_string 的多模态消息内容。
+func (m Message) ObjectStrings() ([]request.ObjectString, error) {
+	if m.ContentType != request.ContentTypeObjectString {
+		return nil, ErrNotObjectStringContent
+	}
+	var objectStrings []request.ObjectString
+	if err := jsoniter.UnmarshalFromString(m.Content, &objectStrings); err != nil {
+		return nil, fmt.Errorf("malformed object_string content: %w", err)
+	}
+	return objectStrings, nil
+}
+
+// Card 解析 content_type 为 card 的卡片消息内容。
+func (m Message) Card() (*Card, error) {
+	if m.ContentType != request.ContentTypeCard {
+		return nil, ErrNotCardContent
+	}
+	card := new(Card)
+	if err := jsoniter.UnmarshalFromString(m.Content, card); err != nil {
+		return nil, fmt.Errorf("malformed card content: %w", err)
+	}
+	if err := jsoniter.UnmarshalFromString(m.Content, &card.Raw); err != nil {
+		return nil, fmt.Errorf("malformed card content: %w", err)
+	}
+	return card, nil
+}
+
+type Card struct {
+	// The type of the card.
+	// 卡片类型。
+	CardType int `json:"card_type"`
+	// The ID of the card template.
+	// 卡片模板 ID。
+	TemplateId string `json:"template_id"`
+	// The address of the card template.
+	// 卡片模板地址。
+	TemplateUrl string `json:"template_url"`
+	// The data used to render the card, either a JSON object or a JSON-encoded string.
+	// 卡片渲染数据，可能为 JSON 对象，也可能为 JSON 编码后的字符串。
+	Data jsoniter.RawMessage `json:"data"`
+
+	// All fields of the card content, including fields not modelled above.
+	// 卡片内容的全部字段，包括上述未建模的字段。
+	Raw map[string]any `json:"-"`
+}
+
+// DataInto 将卡片渲染数据解析到 v 中，兼容 data 为 JSON 编码字符串的情况。
+func (c *Card) DataInto(v any) error {
+	if len(c.Data) == 0 {
+		return errors.New("card data is empty")
+	}
+	data := []byte(c.Data)
+	var encoded string
+	if err := jsoniter.Unmarshal(data, &encoded); err == nil {
+		data = []byte(encoded)
+	}
+	if err := jsoniter.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("malformed card data: %w", err)
+	}
+	return nil
 }
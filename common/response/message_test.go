@@ -0,0 +1,91 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package response
+
+import (
+	"testing"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessage_ObjectStrings(t *testing.T) {
+	testCases := []struct {
+		name    string
+		message Message
+		want    []request.ObjectString
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "text content",
+			message: Message{ContentType: "text", Content: "你好"},
+			wantErr: require.Error,
+		},
+		{
+			name:    "malformed content",
+			message: Message{ContentType: "object_string", Content: `{"type":"text"`},
+			wantErr: require.Error,
+		},
+		{
+			name:    "text and image",
+			message: Message{ContentType: "object_string", Content: `[{"type":"text","text":"看看这张图"},{"type":"image","file_id":"123"}]`},
+			want: []request.ObjectString{
+				{Type: "text", Text: "看看这张图"},
+				{Type: "image", FileId: "123"},
+			},
+			wantErr: require.NoError,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.message.ObjectStrings()
+			tc.wantErr(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestMessage_Card(t *testing.T) {
+	_, err := Message{ContentType: "text", Content: "你好"}.Card()
+	require.Equal(t, ErrNotCardContent, err)
+
+	_, err = Message{ContentType: "card", Content: "not json"}.Card()
+	require.Error(t, err)
+
+	card, err := Message{
+		ContentType: "card",
+		Content:     `{"card_type":3,"template_id":"1","template_url":"https://example.com/t","data":"{\"title\":\"天气\"}","x_extra":true}`,
+	}.Card()
+	require.NoError(t, err)
+	require.Equal(t, 3, card.CardType)
+	require.Equal(t, "1", card.TemplateId)
+	require.Equal(t, "https://example.com/t", card.TemplateUrl)
+	require.Equal(t, true, card.Raw["x_extra"])
+
+	var data struct {
+		Title string `json:"title"`
+	}
+	require.NoError(t, card.DataInto(&data))
+	require.Equal(t, "天气", data.Title)
+
+	card, err = Message{ContentType: "card", Content: `{"card_type":3,"data":{"title":"新闻"}}`}.Card()
+	require.NoError(t, err)
+	require.NoError(t, card.DataInto(&data))
+	require.Equal(t, "新闻", data.Title)
+
+	card, err = Message{ContentType: "card", Content: `{"card_type":3}`}.Card()
+	require.NoError(t, err)
+	require.Error(t, card.DataInto(&data))
+}
@@ -0,0 +1,103 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conversation
+
+import (
+	"context"
+	"sync"
+
+	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/chenmingyong0423/go-coze/internal/bulk"
+)
+
+// MetaDataKeyUserId 是 DeleteUserConversations 用于识别会话所属用户的附加信息键。
+// Coze 返回的会话不包含用户信息，因此需要在创建会话时通过附加信息写入用户 ID。
+const MetaDataKeyUserId = "user_id"
+
+// BulkDeleteError 表示批量删除时部分会话删除失败，Failed 中保存删除失败的会话 ID 及对应的错误。
+type BulkDeleteError = bulk.Error
+
+// DeleteConversations 以不超过 concurrency 的并发数删除多个会话，返回删除成功的会话 ID。
+// 部分会话删除失败时返回 *BulkDeleteError，concurrency 小于 1 时按 1 处理。
+func (c *Conversation) DeleteConversations(ctx context.Context, conversationIds []string, concurrency int) ([]string, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		deleted = make([]string, 0, len(conversationIds))
+		failed  = make(map[string]error)
+		sem     = make(chan struct{}, concurrency)
+	)
+	for _, id := range conversationIds {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			mu.Lock()
+			failed[id] = ctx.Err()
+			mu.Unlock()
+			continue
+		}
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			resp, err := c.DeleteRequest().Do(ctx, id)
+			if err == nil {
+				err = resp.Err()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed[id] = err
+				return
+			}
+			deleted = append(deleted, id)
+		}(id)
+	}
+	wg.Wait()
+
+	if len(failed) > 0 {
+		return deleted, &BulkDeleteError{Op: "delete", Failed: failed}
+	}
+	return deleted, nil
+}
+
+// DeleteUserConversations 删除 Bot 下属于指定用户的所有会话，会话所属用户通过附加信息中的 user_id 识别。
+// 会先遍历出所有匹配的会话，再以不超过 concurrency 的并发数删除，返回删除成功的会话 ID。
+func (c *Conversation) DeleteUserConversations(ctx context.Context, botId, userId string, concurrency int) ([]string, error) {
+	return c.DeleteMatchingConversations(ctx, botId, func(conversation response.Conversation) bool {
+		id, ok := conversation.MetaData[MetaDataKeyUserId].(string)
+		return ok && id == userId
+	}, concurrency)
+}
+
+// DeleteMatchingConversations 删除 Bot 下所有满足 match 的会话。
+func (c *Conversation) DeleteMatchingConversations(ctx context.Context, botId string, match func(conversation response.Conversation) bool, concurrency int) ([]string, error) {
+	var ids []string
+	it := c.ListRequest(botId).Iterator()
+	for it.Next(ctx) {
+		if match(it.Item()) {
+			ids = append(ids, it.Item().Id)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return c.DeleteConversations(ctx, ids, concurrency)
+}
@@ -21,8 +21,10 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
+	"github.com/chenmingyong0423/go-coze/common/pager"
 	"github.com/chenmingyong0423/go-coze/common/request"
 	"github.com/chenmingyong0423/go-coze/common/response"
 	jsoniter "github.com/json-iterator/go"
@@ -31,12 +33,30 @@ import (
 const (
 	InternationalCreateUrl   = "https://api.coze.com/v1/conversation/create"
 	InternationalRetrieveUrl = "https://api.coze.com/v1/conversation/retrieve"
+	InternationalListUrl     = "https://api.coze.com/v1/conversations"
+	InternationalClearUrl    = "https://api.coze.com/v1/conversations/%s/clear"
+	InternationalUpdateUrl   = "https://api.coze.com/v1/conversations/%s"
+	InternationalDeleteUrl   = "https://api.coze.com/v1/conversations/%s"
 
 	createUrl             = "https://api.coze.cn/v1/conversation/create"
 	retrieveUrl           = "https://api.coze.cn/v1/conversation/retrieve"
+	listUrl               = "https://api.coze.cn/v1/conversations"
+	clearUrl              = "https://api.coze.cn/v1/conversations/%s/clear"
+	updateUrl             = "https://api.coze.cn/v1/conversations/%s"
+	deleteUrl             = "https://api.coze.cn/v1/conversations/%s"
 	HeaderAuthorization   = "authorization"
 	HeaderContentType     = "Content-Type"
 	HeaderApplicationJson = "application/json"
+
+	SortOrderAsc  = "ASC"
+	SortOrderDesc = "DESC"
+
+	// DefaultListPageSize 查询会话列表时默认的每页数量。
+	DefaultListPageSize = 50
+	// MaxListPageSize 查询会话列表时每页的最大数量。
+	MaxListPageSize = 50
+	// MaxCreateMessages 创建会话时最多可携带的消息数量。
+	MaxCreateMessages = 50
 )
 
 type Conversation struct {
@@ -59,10 +79,40 @@ func (c *Conversation) RetrieveRequest() *RetrieveRequest {
 	}
 }
 
+func (c *Conversation) ClearRequest() *ClearRequest {
+	return &ClearRequest{
+		conversation: c,
+	}
+}
+
+func (c *Conversation) UpdateRequest() *UpdateRequest {
+	return &UpdateRequest{
+		conversation: c,
+	}
+}
+
+func (c *Conversation) DeleteRequest() *DeleteRequest {
+	return &DeleteRequest{
+		conversation: c,
+	}
+}
+
+func (c *Conversation) ListRequest(botId string) *ListRequest {
+	return &ListRequest{
+		conversation: c,
+		botId:        botId,
+		pageNum:      1,
+		pageSize:     DefaultListPageSize,
+	}
+}
+
 type CreateRequest struct {
 	conversation *Conversation
 
-	timeout  time.Duration
+	timeout time.Duration
+	// 是否跳过发送请求前的参数校验。
+	skipValidation bool
+
 	Messages []request.EnterMessage `json:"messages,omitempty"`
 	MetaData map[string]any         `json:"meta_data,omitempty"`
 }
@@ -82,7 +132,30 @@ func (r *CreateRequest) WithTimeout(timeout time.Duration) *CreateRequest {
 	return r
 }
 
+// WithSkipValidation 设置是否跳过 Do 发送请求前的参数校验。
+func (r *CreateRequest) WithSkipValidation(skip bool) *CreateRequest {
+	r.skipValidation = skip
+	return r
+}
+
+// Validate 校验请求参数，包括消息数量、消息的角色和内容类型以及附加信息的大小限制。
+func (r *CreateRequest) Validate() error {
+	if len(r.Messages) > MaxCreateMessages {
+		return request.NewValidationError("messages", fmt.Sprintf("must contain at most %d messages", MaxCreateMessages))
+	}
+	if err := request.ValidateMessages("messages", r.Messages); err != nil {
+		return err
+	}
+	return request.ValidateMetaData("meta_data", r.MetaData)
+}
+
 func (r *CreateRequest) Do(ctx context.Context) (*response.DataResponse[response.Conversation], error) {
+	if !r.skipValidation {
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	body, err := jsoniter.Marshal(r)
 	if err != nil {
 		return nil, err
@@ -99,7 +172,7 @@ func (r *CreateRequest) Do(ctx context.Context) (*response.DataResponse[response
 
 	client := http.DefaultClient
 	if r.timeout != 0 {
-		client.Timeout = r.timeout
+		client = &http.Client{Timeout: r.timeout}
 	}
 
 	httpResp, err := client.Do(req)
@@ -160,7 +233,356 @@ func (r *RetrieveRequest) Do(ctx context.Context, conversation string) (*respons
 
 	client := http.DefaultClient
 	if r.timeout != 0 {
-		client.Timeout = r.timeout
+		client = &http.Client{Timeout: r.timeout}
+	}
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, &response.HttpErrorResponse{
+			Status:     httpResp.Status,
+			StatusCode: httpResp.StatusCode,
+			Body:       data,
+		}
+	}
+	if err = jsoniter.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+type ListRequest struct {
+	conversation *Conversation
+
+	timeout time.Duration
+	// 是否跳过发送请求前的参数校验。
+	skipValidation bool
+
+	botId     string
+	pageNum   int
+	pageSize  int
+	sortOrder string
+}
+
+func (r *ListRequest) WithTimeout(timeout time.Duration) *ListRequest {
+	r.timeout = timeout
+	return r
+}
+
+// WithSkipValidation 设置是否跳过 Do 发送请求前的参数校验。
+func (r *ListRequest) WithSkipValidation(skip bool) *ListRequest {
+	r.skipValidation = skip
+	return r
+}
+
+// WithPageNum 设置页码，从 1 开始，默认为 1。
+func (r *ListRequest) WithPageNum(pageNum int) *ListRequest {
+	r.pageNum = pageNum
+	return r
+}
+
+// WithPageSize 设置每页的数量，取值范围为 1 ~ 50，默认为 50。
+func (r *ListRequest) WithPageSize(pageSize int) *ListRequest {
+	r.pageSize = pageSize
+	return r
+}
+
+// WithSortOrder 设置按创建时间排序的方式，可选值为 ASC 和 DESC。
+func (r *ListRequest) WithSortOrder(sortOrder string) *ListRequest {
+	r.sortOrder = sortOrder
+	return r
+}
+
+// Validate 校验请求参数，包括 bot_id 必填、页码和每页数量的范围以及排序方式的取值。
+func (r *ListRequest) Validate() error {
+	if err := request.ValidateRequired("bot_id", r.botId); err != nil {
+		return err
+	}
+	if r.pageNum < 1 {
+		return request.NewValidationError("page_num", "must be greater than 0")
+	}
+	if r.pageSize < 1 || r.pageSize > MaxListPageSize {
+		return request.NewValidationError("page_size", fmt.Sprintf("must be between 1 and %d", MaxListPageSize))
+	}
+	if r.sortOrder != "" && r.sortOrder != SortOrderAsc && r.sortOrder != SortOrderDesc {
+		return request.NewValidationError("sort_order", fmt.Sprintf("unsupported sort order %q", r.sortOrder))
+	}
+	return nil
+}
+
+func (r *ListRequest) Do(ctx context.Context) (*response.DataResponse[ListData], error) {
+	if !r.skipValidation {
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := new(response.DataResponse[ListData])
+
+	// 构建查询参数
+	params := url.Values{}
+	params.Add("bot_id", r.botId)
+	params.Add("page_num", strconv.Itoa(r.pageNum))
+	params.Add("page_size", strconv.Itoa(r.pageSize))
+	if r.sortOrder != "" {
+		params.Add("sort_order", r.sortOrder)
+	}
+
+	u, err := url.Parse(listUrl)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add(HeaderContentType, HeaderApplicationJson)
+	req.Header.Add(HeaderAuthorization, fmt.Sprintf("Bearer %s", r.conversation.authorization))
+
+	client := http.DefaultClient
+	if r.timeout != 0 {
+		client = &http.Client{Timeout: r.timeout}
+	}
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, &response.HttpErrorResponse{
+			Status:     httpResp.Status,
+			StatusCode: httpResp.StatusCode,
+			Body:       data,
+		}
+	}
+	if err = jsoniter.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// Iterator 返回一个从当前页码开始、按需逐页查询的会话迭代器。
+func (r *ListRequest) Iterator() *pager.Pager[response.Conversation] {
+	req := *r
+	return pager.NewNumberPager(req.pageNum, func(ctx context.Context, pageNum int) ([]response.Conversation, bool, error) {
+		resp, err := req.WithPageNum(pageNum).Do(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		if err = resp.Err(); err != nil {
+			return nil, false, err
+		}
+		return resp.Data.Conversations, resp.Data.HasMore, nil
+	})
+}
+
+// All 遍历并返回所有会话。
+func (r *ListRequest) All(ctx context.Context) ([]response.Conversation, error) {
+	return r.Iterator().All(ctx)
+}
+
+// ClearRequest 清除会话中的上下文，会话中的历史消息仍然保留，但之后的对话不会再参考清除前的上下文。
+// 每次清除都会在会话中开启一个新的 section。
+type ClearRequest struct {
+	conversation *Conversation
+
+	timeout time.Duration
+}
+
+func (r *ClearRequest) WithTimeout(timeout time.Duration) *ClearRequest {
+	r.timeout = timeout
+	return r
+}
+
+func (r *ClearRequest) Do(ctx context.Context, conversationId string) (*response.DataResponse[response.Section], error) {
+	if err := request.ValidateRequired("conversation_id", conversationId); err != nil {
+		return nil, err
+	}
+
+	resp := new(response.DataResponse[response.Section])
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(clearUrl, url.PathEscape(conversationId)), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add(HeaderContentType, HeaderApplicationJson)
+	req.Header.Add(HeaderAuthorization, fmt.Sprintf("Bearer %s", r.conversation.authorization))
+
+	client := http.DefaultClient
+	if r.timeout != 0 {
+		client = &http.Client{Timeout: r.timeout}
+	}
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, &response.HttpErrorResponse{
+			Status:     httpResp.Status,
+			StatusCode: httpResp.StatusCode,
+			Body:       data,
+		}
+	}
+	if err = jsoniter.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+type UpdateRequest struct {
+	conversation *Conversation
+
+	timeout time.Duration
+	// 是否跳过发送请求前的参数校验。
+	skipValidation bool
+
+	// 会话名称。
+	Name string `json:"name,omitempty"`
+	// 会话的附加信息，会整体替换原有的附加信息。
+	MetaData map[string]any `json:"meta_data,omitempty"`
+}
+
+func (r *UpdateRequest) WithTimeout(timeout time.Duration) *UpdateRequest {
+	r.timeout = timeout
+	return r
+}
+
+// WithSkipValidation 设置是否跳过 Do 发送请求前的参数校验。
+func (r *UpdateRequest) WithSkipValidation(skip bool) *UpdateRequest {
+	r.skipValidation = skip
+	return r
+}
+
+func (r *UpdateRequest) WithName(name string) *UpdateRequest {
+	r.Name = name
+	return r
+}
+
+func (r *UpdateRequest) WithMetaData(metaData map[string]any) *UpdateRequest {
+	r.MetaData = metaData
+	return r
+}
+
+// Validate 校验请求参数，包括至少需要修改一项以及附加信息的大小限制。
+func (r *UpdateRequest) Validate() error {
+	if r.Name == "" && r.MetaData == nil {
+		return request.NewValidationError("name", "name or meta_data is required")
+	}
+	return request.ValidateMetaData("meta_data", r.MetaData)
+}
+
+func (r *UpdateRequest) Do(ctx context.Context, conversationId string) (*response.DataResponse[response.Conversation], error) {
+	if err := request.ValidateRequired("conversation_id", conversationId); err != nil {
+		return nil, err
+	}
+	if !r.skipValidation {
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	body, err := jsoniter.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := new(response.DataResponse[response.Conversation])
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, fmt.Sprintf(updateUrl, url.PathEscape(conversationId)), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add(HeaderContentType, HeaderApplicationJson)
+	req.Header.Add(HeaderAuthorization, fmt.Sprintf("Bearer %s", r.conversation.authorization))
+
+	client := http.DefaultClient
+	if r.timeout != 0 {
+		client = &http.Client{Timeout: r.timeout}
+	}
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, &response.HttpErrorResponse{
+			Status:     httpResp.Status,
+			StatusCode: httpResp.StatusCode,
+			Body:       data,
+		}
+	}
+	if err = jsoniter.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+type DeleteRequest struct {
+	conversation *Conversation
+
+	timeout time.Duration
+}
+
+func (r *DeleteRequest) WithTimeout(timeout time.Duration) *DeleteRequest {
+	r.timeout = timeout
+	return r
+}
+
+// Do 删除会话，会话中的消息也会被一并删除且无法恢复。
+func (r *DeleteRequest) Do(ctx context.Context, conversationId string) (*response.BaseResponse, error) {
+	if err := request.ValidateRequired("conversation_id", conversationId); err != nil {
+		return nil, err
+	}
+
+	resp := new(response.BaseResponse)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, fmt.Sprintf(deleteUrl, url.PathEscape(conversationId)), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add(HeaderContentType, HeaderApplicationJson)
+	req.Header.Add(HeaderAuthorization, fmt.Sprintf("Bearer %s", r.conversation.authorization))
+
+	client := http.DefaultClient
+	if r.timeout != 0 {
+		client = &http.Client{Timeout: r.timeout}
 	}
 
 	httpResp, err := client.Do(req)
@@ -17,12 +17,17 @@ package conversation
 import (
 	"context"
 	"errors"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/chenmingyong0423/go-coze/common/request"
 	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/chenmingyong0423/go-coze/internal/cozetest"
 	"github.com/stretchr/testify/require"
 )
 
@@ -51,6 +56,22 @@ func TestCreateRequest_Do(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:          "invalid message role",
+			ctx:           context.Background(),
+			authorization: os.Getenv("COZE_TOKEN"),
+			messages: []request.EnterMessage{
+				request.NewEnterMessageBuilder().Role("system").Content("你好").ContentType("text").Build(),
+			},
+			wantErr: requireValidationError,
+		},
+		{
+			name:          "too many messages",
+			ctx:           context.Background(),
+			authorization: os.Getenv("COZE_TOKEN"),
+			messages:      make([]request.EnterMessage, MaxCreateMessages+1),
+			wantErr:       requireValidationError,
+		},
 		{
 			name:          "success",
 			ctx:           context.Background(),
@@ -88,3 +109,217 @@ func TestRetrieveRequest_Do(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, retrieveResp.Data, createResp.Data)
 }
+
+func TestListRequest_Do(t *testing.T) {
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		authorization string
+		botId         string
+		pageNum       int
+		pageSize      int
+		sortOrder     string
+		want          func(t *testing.T, resp *response.DataResponse[ListData])
+		wantErr       require.ErrorAssertionFunc
+	}{
+		{
+			name:          "empty botId",
+			ctx:           context.Background(),
+			authorization: os.Getenv("COZE_TOKEN"),
+			botId:         "",
+			pageNum:       1,
+			pageSize:      10,
+			wantErr:       requireValidationError,
+		},
+		{
+			name:          "page size out of range",
+			ctx:           context.Background(),
+			authorization: os.Getenv("COZE_TOKEN"),
+			botId:         "bot",
+			pageNum:       1,
+			pageSize:      MaxListPageSize + 1,
+			wantErr:       requireValidationError,
+		},
+		{
+			name:          "invalid sort order",
+			ctx:           context.Background(),
+			authorization: os.Getenv("COZE_TOKEN"),
+			botId:         "bot",
+			pageNum:       1,
+			pageSize:      10,
+			sortOrder:     "random",
+			wantErr:       requireValidationError,
+		},
+		{
+			name:          "success",
+			ctx:           context.Background(),
+			authorization: os.Getenv("COZE_TOKEN"),
+			botId:         os.Getenv("COZE_BOT_ID"),
+			pageNum:       1,
+			pageSize:      10,
+			sortOrder:     SortOrderDesc,
+			want: func(t *testing.T, resp *response.DataResponse[ListData]) {
+				require.Equal(t, 0, resp.Code)
+				t.Log(resp.Data)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewConversation(tt.authorization).ListRequest(tt.botId).
+				WithPageNum(tt.pageNum).WithPageSize(tt.pageSize).WithSortOrder(tt.sortOrder).Do(tt.ctx)
+			if err != nil {
+				t.Log(err)
+				require.NotNil(t, tt.wantErr)
+				tt.wantErr(t, err)
+			}
+			if tt.want != nil {
+				tt.want(t, got)
+			}
+		})
+	}
+}
+
+func TestListIterator(t *testing.T) {
+	var pages []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/conversations", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		require.Equal(t, "bot", query.Get("bot_id"))
+		require.Equal(t, "2", query.Get("page_size"))
+		pageNum, _ := strconv.Atoi(query.Get("page_num"))
+		pages = append(pages, query.Get("page_num"))
+
+		var data ListData
+		for i := (pageNum - 1) * 2; i < pageNum*2 && i < 5; i++ {
+			data.Conversations = append(data.Conversations, response.Conversation{Id: strconv.Itoa(i)})
+		}
+		data.HasMore = pageNum*2 < 5
+		cozetest.WriteData(w, data)
+	})
+	cozetest.NewServer(t, mux)
+
+	conversations, err := NewConversation("token").ListRequest("bot").WithPageSize(2).All(context.Background())
+	require.NoError(t, err)
+	require.Len(t, conversations, 5)
+	require.Equal(t, "4", conversations[4].Id)
+	require.Equal(t, []string{"1", "2", "3"}, pages)
+
+	// 提前结束遍历时不会查询后续的页面。
+	pages = nil
+	it := NewConversation("token").ListRequest("bot").WithPageSize(2).Iterator()
+	require.True(t, it.Next(context.Background()))
+	require.True(t, it.Next(context.Background()))
+	require.Equal(t, "1", it.Item().Id)
+	require.NoError(t, it.Err())
+	require.Equal(t, []string{"1"}, pages)
+}
+
+func requireValidationError(t require.TestingT, err error, i ...interface{}) {
+	var validationErr *request.ValidationError
+	require.True(t, errors.As(err, &validationErr), "unexpected error: %v", err)
+}
+
+func TestClearRequest_Do(t *testing.T) {
+	_, err := NewConversation(os.Getenv("COZE_TOKEN")).ClearRequest().Do(context.Background(), "")
+	requireValidationError(t, err)
+
+	conversation := NewConversation(os.Getenv("COZE_TOKEN"))
+	createResp, err := conversation.CreateRequest().Do(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, createResp.Data)
+	clearResp, err := conversation.ClearRequest().Do(context.Background(), createResp.Data.Id)
+	require.NoError(t, err)
+	require.Equal(t, 0, clearResp.Code)
+	require.Equal(t, createResp.Data.Id, clearResp.Data.ConversationId)
+	require.NotZero(t, clearResp.Data.Id)
+
+	retrieveResp, err := conversation.RetrieveRequest().Do(context.Background(), createResp.Data.Id)
+	require.NoError(t, err)
+	require.Equal(t, clearResp.Data.Id, retrieveResp.Data.LastSectionId)
+}
+
+func TestUpdateRequest_Do(t *testing.T) {
+	_, err := NewConversation(os.Getenv("COZE_TOKEN")).UpdateRequest().Do(context.Background(), "123")
+	requireValidationError(t, err)
+
+	conversation := NewConversation(os.Getenv("COZE_TOKEN"))
+	createResp, err := conversation.CreateRequest().WithMetaData(map[string]any{"tenant": "a"}).Do(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, createResp.Data)
+	updateResp, err := conversation.UpdateRequest().WithName("go-coze").WithMetaData(map[string]any{"tenant": "b"}).
+		Do(context.Background(), createResp.Data.Id)
+	require.NoError(t, err)
+	require.Equal(t, 0, updateResp.Code)
+
+	retrieveResp, err := conversation.RetrieveRequest().Do(context.Background(), createResp.Data.Id)
+	require.NoError(t, err)
+	require.Equal(t, "b", retrieveResp.Data.MetaData["tenant"])
+}
+
+func TestDeleteRequest_Do(t *testing.T) {
+	conversation := NewConversation(os.Getenv("COZE_TOKEN"))
+	createResp, err := conversation.CreateRequest().Do(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, createResp.Data)
+	deleteResp, err := conversation.DeleteRequest().Do(context.Background(), createResp.Data.Id)
+	require.NoError(t, err)
+	require.Equal(t, 0, deleteResp.Code)
+}
+
+func TestConversation_DeleteUserConversations(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		deleted   []string
+		inFlight  int
+		maxFlight int
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/conversations", func(w http.ResponseWriter, r *http.Request) {
+		pageNum, _ := strconv.Atoi(r.URL.Query().Get("page_num"))
+		var data ListData
+		for i := (pageNum - 1) * 10; i < pageNum*10 && i < 20; i++ {
+			userId := "alice"
+			if i%4 == 0 {
+				userId = "bob"
+			}
+			data.Conversations = append(data.Conversations, response.Conversation{Id: strconv.Itoa(i), MetaData: map[string]any{MetaDataKeyUserId: userId}})
+		}
+		data.HasMore = pageNum*10 < 20
+		cozetest.WriteData(w, data)
+	})
+	mux.HandleFunc("/v1/conversations/", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodDelete, r.Method)
+		id := strings.TrimPrefix(r.URL.Path, "/v1/conversations/")
+		mu.Lock()
+		inFlight++
+		if inFlight > maxFlight {
+			maxFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		if id == "7" {
+			cozetest.WriteJSON(w, response.BaseResponse{Code: 4101, Msg: "permission denied"})
+			return
+		}
+		mu.Lock()
+		deleted = append(deleted, id)
+		mu.Unlock()
+		cozetest.WriteJSON(w, response.BaseResponse{})
+	})
+	cozetest.NewServer(t, mux)
+
+	ids, err := NewConversation("token").DeleteUserConversations(context.Background(), "bot", "alice", 3)
+	var bulkErr *BulkDeleteError
+	require.True(t, errors.As(err, &bulkErr))
+	require.Len(t, bulkErr.Failed, 1)
+	require.Contains(t, bulkErr.Failed, "7")
+	require.Len(t, ids, 14)
+	require.ElementsMatch(t, ids, deleted)
+	require.NotContains(t, ids, "4")
+	require.True(t, maxFlight <= 3)
+}
@@ -0,0 +1,474 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package file 用于上传文件和查询已上传文件的信息，上传得到的文件 ID 可直接用于多模态消息内容。
+package file
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/chenmingyong0423/go-coze/common/response"
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	InternationalUploadUrl   = "https://api.coze.com/v1/files/upload"
+	InternationalRetrieveUrl = "https://api.coze.com/v1/files/retrieve"
+
+	uploadUrl             = "https://api.coze.cn/v1/files/upload"
+	retrieveUrl           = "https://api.coze.cn/v1/files/retrieve"
+	HeaderAuthorization   = "authorization"
+	HeaderContentType     = "Content-Type"
+	HeaderApplicationJson = "application/json"
+
+	// MaxUploadSize 单个文件的最大字节数。
+	MaxUploadSize = 512 << 20
+
+	// 上传请求中文件对应的表单字段名。
+	formFieldFile = "file"
+	// 用于检测文件内容类型的字节数。
+	sniffLen = 512
+)
+
+// ErrFileTooLarge 表示文件超过 MaxUploadSize。
+var ErrFileTooLarge = fmt.Errorf("file size exceeds the limit of %d bytes", MaxUploadSize)
+
+type File struct {
+	authorization string
+}
+
+func NewFile(authorization string) *File {
+	return &File{authorization: authorization}
+}
+
+func (f *File) UploadRequest() *UploadRequest {
+	return &UploadRequest{file: f}
+}
+
+func (f *File) RetrieveRequest() *RetrieveRequest {
+	return &RetrieveRequest{file: f}
+}
+
+type UploadRequest struct {
+	file *File
+
+	timeout time.Duration
+	// 是否跳过发送请求前的参数校验。
+	skipValidation bool
+
+	fileName    string
+	contentType string
+	reader      io.Reader
+	path        string
+	progress    ProgressFunc
+}
+
+// ProgressFunc 报告上传进度，sent 为已发送的文件字节数，total 为文件总字节数，无法预知文件大小时 total 为 -1。
+// 该函数在发送请求体的 goroutine 中调用，不应阻塞。
+type ProgressFunc func(sent, total int64)
+
+func (r *UploadRequest) WithTimeout(timeout time.Duration) *UploadRequest {
+	r.timeout = timeout
+	return r
+}
+
+// WithSkipValidation 设置是否跳过 Do 发送请求前的参数校验。
+func (r *UploadRequest) WithSkipValidation(skip bool) *UploadRequest {
+	r.skipValidation = skip
+	return r
+}
+
+// WithReader 从 reader 中读取文件内容，fileName 为上传后的文件名，Coze 会根据扩展名判断文件类型。
+func (r *UploadRequest) WithReader(fileName string, reader io.Reader) *UploadRequest {
+	r.fileName = fileName
+	r.reader = reader
+	r.path = ""
+	return r
+}
+
+// WithPath 上传本地文件，文件名默认为路径中的文件名。
+func (r *UploadRequest) WithPath(path string) *UploadRequest {
+	r.path = path
+	r.fileName = filepath.Base(path)
+	r.reader = nil
+	return r
+}
+
+// WithFileName 修改上传后的文件名。
+func (r *UploadRequest) WithFileName(fileName string) *UploadRequest {
+	r.fileName = fileName
+	return r
+}
+
+// WithContentType 指定文件的 MIME 类型，未指定时根据文件扩展名推断，无法推断时根据文件内容检测。
+func (r *UploadRequest) WithContentType(contentType string) *UploadRequest {
+	r.contentType = contentType
+	return r
+}
+
+// WithProgress 设置上传进度回调，每次向请求体写入文件内容后调用。
+func (r *UploadRequest) WithProgress(progress ProgressFunc) *UploadRequest {
+	r.progress = progress
+	return r
+}
+
+// Validate 校验请求参数，包括文件来源、文件名、空文件以及本地文件的大小限制。
+func (r *UploadRequest) Validate() error {
+	if r.reader == nil && r.path == "" {
+		return request.NewValidationError("file", "is required, use WithReader or WithPath")
+	}
+	if err := request.ValidateRequired("file_name", r.fileName); err != nil {
+		return err
+	}
+	if r.path != "" {
+		info, err := os.Stat(r.path)
+		if err != nil {
+			return request.NewValidationError("file", err.Error())
+		}
+		if info.IsDir() {
+			return request.NewValidationError("file", fmt.Sprintf("%s is a directory", r.path))
+		}
+		if info.Size() == 0 {
+			return request.NewValidationError("file", "is empty")
+		}
+		if info.Size() > MaxUploadSize {
+			return request.NewValidationError("file", ErrFileTooLarge.Error())
+		}
+	} else if size(r.reader) == 0 {
+		return request.NewValidationError("file", "is empty")
+	}
+	return nil
+}
+
+// open 返回文件内容，调用方负责关闭。
+func (r *UploadRequest) open() (io.ReadCloser, error) {
+	if r.path != "" {
+		return os.Open(r.path)
+	}
+	if r.reader == nil {
+		return nil, errors.New("file: no file to upload")
+	}
+	return io.NopCloser(r.reader), nil
+}
+
+// size 返回文件的字节数，无法预知时返回 -1。
+// 对于 reader，支持 Stat（如 os.File）、Size（如 io.SectionReader）和 Len（如 strings.Reader）方法。
+func size(content io.Reader) int64 {
+	var total int64
+	switch c := content.(type) {
+	case interface{ Len() int }:
+		// bytes.Reader、strings.Reader 和 bytes.Buffer 的 Len 即为未读取的字节数。
+		return int64(c.Len())
+	case interface{ Stat() (os.FileInfo, error) }:
+		info, err := c.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		total = info.Size()
+	case interface{ Size() int64 }:
+		total = c.Size()
+	default:
+		return -1
+	}
+	// Stat 和 Size 返回的是完整大小，需要减去当前的读取位置，只统计尚未读取的部分。
+	if seeker, ok := content.(io.Seeker); ok {
+		offset, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		total -= offset
+	}
+	if total < 0 {
+		return 0
+	}
+	return total
+}
+
+// peek 读取一个字节判断内容是否为空，返回包含已读取字节的完整内容。
+func peek(content io.Reader) (bool, io.Reader, error) {
+	head := make([]byte, 1)
+	n, err := io.ReadFull(content, head)
+	if err == io.EOF {
+		return true, content, nil
+	}
+	if err != nil {
+		return false, nil, err
+	}
+	return false, io.MultiReader(bytes.NewReader(head[:n]), content), nil
+}
+
+// detectContentType 返回文件的 MIME 类型，以及包含检测时已读取的字节的完整内容。
+func (r *UploadRequest) detectContentType(content io.Reader) (string, io.Reader, error) {
+	if r.contentType != "" {
+		return r.contentType, content, nil
+	}
+	if mediaType := response.MediaType(r.fileName); mediaType != "" {
+		return mediaType, content, nil
+	}
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(content, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", nil, err
+	}
+	head = head[:n]
+	return http.DetectContentType(head), io.MultiReader(bytes.NewReader(head), content), nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func createFormFile(w *multipart.Writer, fileName, contentType string) (io.Writer, error) {
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, formFieldFile, quoteEscaper.Replace(fileName)))
+	h.Set(HeaderContentType, contentType)
+	return w.CreatePart(h)
+}
+
+// Do 上传文件，成功后返回的文件可通过 response.File.ObjectString 用于多模态消息内容。
+func (r *UploadRequest) Do(ctx context.Context) (*response.DataResponse[response.File], error) {
+	if !r.skipValidation {
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	content, err := r.open()
+	if err != nil {
+		return nil, err
+	}
+	var source io.Reader = content
+	if r.reader != nil {
+		source = r.reader
+	}
+	total := size(source)
+	if total > MaxUploadSize {
+		content.Close()
+		return nil, ErrFileTooLarge
+	}
+
+	contentType, reader, err := r.detectContentType(content)
+	if err != nil {
+		content.Close()
+		return nil, err
+	}
+	empty := total == 0
+	if total < 0 {
+		// 无法得知大小的内容需要先读取一个字节确认不是空文件。
+		empty, reader, err = peek(reader)
+		if err != nil {
+			content.Close()
+			return nil, err
+		}
+	}
+	if empty {
+		content.Close()
+		return nil, request.NewValidationError("file", "is empty")
+	}
+
+	// 通过管道边读取文件边发送请求体，避免将整个文件读入内存
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	written := make(chan error, 1)
+	go func() {
+		defer content.Close()
+		err := r.writeBody(mw, contentType, reader, total)
+		pw.CloseWithError(err)
+		written <- err
+	}()
+
+	resp := new(response.DataResponse[response.File])
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadUrl, pr)
+	if err != nil {
+		pr.CloseWithError(err)
+		<-written
+		return nil, err
+	}
+	if total >= 0 {
+		overhead, err := multipartOverhead(mw.Boundary(), r.fileName, contentType)
+		if err != nil {
+			pr.CloseWithError(err)
+			<-written
+			return nil, err
+		}
+		req.ContentLength = overhead + total
+	}
+	req.Header.Add(HeaderContentType, mw.FormDataContentType())
+	req.Header.Add(HeaderAuthorization, fmt.Sprintf("Bearer %s", r.file.authorization))
+
+	client := http.DefaultClient
+	if r.timeout != 0 {
+		client = &http.Client{Timeout: r.timeout}
+	}
+
+	httpResp, err := client.Do(req)
+	// 服务端可能在读取完请求体之前就返回响应，关闭管道以结束写入请求体的 goroutine
+	pr.CloseWithError(errors.New("file: upload request finished"))
+	if writeErr := <-written; writeErr == ErrFileTooLarge {
+		err = writeErr
+	}
+	if err != nil {
+		if httpResp != nil {
+			httpResp.Body.Close()
+		}
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, &response.HttpErrorResponse{
+			Status:     httpResp.Status,
+			StatusCode: httpResp.StatusCode,
+			Body:       data,
+		}
+	}
+	if err = jsoniter.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// writeBody 将文件以 multipart 格式写入请求体。
+func (r *UploadRequest) writeBody(mw *multipart.Writer, contentType string, content io.Reader, total int64) error {
+	part, err := createFormFile(mw, r.fileName, contentType)
+	if err != nil {
+		return err
+	}
+	var src io.Reader = io.LimitReader(content, MaxUploadSize+1)
+	if r.progress != nil {
+		src = &progressReader{reader: src, total: total, progress: r.progress}
+	}
+	n, err := io.Copy(part, src)
+	if err != nil {
+		return err
+	}
+	if n > MaxUploadSize {
+		return ErrFileTooLarge
+	}
+	return mw.Close()
+}
+
+// multipartOverhead 返回请求体中除文件内容以外的字节数，用于在文件大小已知时设置 Content-Length。
+func multipartOverhead(boundary, fileName, contentType string) (int64, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	if err := mw.SetBoundary(boundary); err != nil {
+		return 0, err
+	}
+	if _, err := createFormFile(mw, fileName, contentType); err != nil {
+		return 0, err
+	}
+	if err := mw.Close(); err != nil {
+		return 0, err
+	}
+	return int64(buf.Len()), nil
+}
+
+type progressReader struct {
+	reader   io.Reader
+	sent     int64
+	total    int64
+	progress ProgressFunc
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.reader.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		p.progress(p.sent, p.total)
+	}
+	return n, err
+}
+
+type RetrieveRequest struct {
+	file *File
+
+	timeout time.Duration
+}
+
+func (r *RetrieveRequest) WithTimeout(timeout time.Duration) *RetrieveRequest {
+	r.timeout = timeout
+	return r
+}
+
+func (r *RetrieveRequest) Do(ctx context.Context, fileId string) (*response.DataResponse[response.File], error) {
+	if err := request.ValidateRequired("file_id", fileId); err != nil {
+		return nil, err
+	}
+
+	resp := new(response.DataResponse[response.File])
+
+	params := url.Values{}
+	params.Add("file_id", fileId)
+
+	u, err := url.Parse(retrieveUrl)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add(HeaderContentType, HeaderApplicationJson)
+	req.Header.Add(HeaderAuthorization, fmt.Sprintf("Bearer %s", r.file.authorization))
+
+	client := http.DefaultClient
+	if r.timeout != 0 {
+		client = &http.Client{Timeout: r.timeout}
+	}
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, &response.HttpErrorResponse{
+			Status:     httpResp.Status,
+			StatusCode: httpResp.StatusCode,
+			Body:       data,
+		}
+	}
+	if err = jsoniter.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
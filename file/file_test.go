@@ -0,0 +1,125 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/chenmingyong0423/go-coze/internal/cozetest"
+	"github.com/stretchr/testify/require"
+)
+
+// uploaded 记录本地服务收到的文件。
+type uploaded struct {
+	fileName    string
+	contentType string
+	content     string
+}
+
+func newFakeFiles(t *testing.T) *uploaded {
+	got := &uploaded{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/files/upload", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get(HeaderAuthorization))
+		f, header, err := r.FormFile(formFieldFile)
+		require.NoError(t, err)
+		defer f.Close()
+		content, err := io.ReadAll(f)
+		require.NoError(t, err)
+		got.fileName, got.contentType, got.content = header.Filename, header.Header.Get(HeaderContentType), string(content)
+		cozetest.WriteData(w, response.File{Id: "file-1", Bytes: int64(len(content)), CreatedAt: 1700000000, FileName: header.Filename})
+	})
+	mux.HandleFunc("/v1/files/retrieve", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "file-1", r.URL.Query().Get("file_id"))
+		cozetest.WriteData(w, response.File{Id: "file-1", Bytes: 5, FileName: "a.png"})
+	})
+	cozetest.NewServer(t, mux)
+	return got
+}
+
+func requireValidationError(t *testing.T, err error) {
+	var validationErr *request.ValidationError
+	require.True(t, errors.As(err, &validationErr), err)
+}
+
+func TestUploadRequest_Do(t *testing.T) {
+	t.Run("reader", func(t *testing.T) {
+		got := newFakeFiles(t)
+		resp, err := NewFile("token").UploadRequest().WithReader("报告.pdf", strings.NewReader("%PDF-1.4")).Do(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "file-1", resp.Data.Id)
+		require.Equal(t, &uploaded{fileName: "报告.pdf", contentType: "application/pdf", content: "%PDF-1.4"}, got)
+		require.Equal(t, request.ObjectString{Type: request.ObjectStringTypeFile, FileId: "file-1"}, resp.Data.ObjectString())
+	})
+
+	t.Run("detect content type from content", func(t *testing.T) {
+		got := newFakeFiles(t)
+		_, err := NewFile("token").UploadRequest().WithReader("noext", strings.NewReader("\x89PNG\r\n\x1a\nrest")).Do(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "image/png", got.contentType)
+		require.Equal(t, "\x89PNG\r\n\x1a\nrest", got.content)
+	})
+
+	t.Run("path with explicit content type", func(t *testing.T) {
+		got := newFakeFiles(t)
+		path := filepath.Join(t.TempDir(), "voice.bin")
+		require.NoError(t, os.WriteFile(path, []byte("audio"), 0o600))
+		_, err := NewFile("token").UploadRequest().WithPath(path).WithFileName("voice.mp3").WithContentType("audio/mpeg").Do(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, &uploaded{fileName: "voice.mp3", contentType: "audio/mpeg", content: "audio"}, got)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		dir := t.TempDir()
+		empty := filepath.Join(dir, "empty.txt")
+		require.NoError(t, os.WriteFile(empty, nil, 0o600))
+		large := filepath.Join(dir, "large.bin")
+		f, err := os.Create(large)
+		require.NoError(t, err)
+		require.NoError(t, f.Truncate(MaxUploadSize+1))
+		require.NoError(t, f.Close())
+
+		for _, req := range []*UploadRequest{
+			NewFile("token").UploadRequest(),
+			NewFile("token").UploadRequest().WithReader("", strings.NewReader("a")),
+			NewFile("token").UploadRequest().WithPath(filepath.Join(dir, "missing.txt")),
+			NewFile("token").UploadRequest().WithPath(dir),
+			NewFile("token").UploadRequest().WithPath(empty),
+			NewFile("token").UploadRequest().WithPath(large),
+		} {
+			_, err := req.Do(context.Background())
+			requireValidationError(t, err)
+		}
+	})
+}
+
+func TestRetrieveRequest_Do(t *testing.T) {
+	newFakeFiles(t)
+	resp, err := NewFile("token").RetrieveRequest().Do(context.Background(), "file-1")
+	require.NoError(t, err)
+	require.Equal(t, request.ObjectString{Type: request.ObjectStringTypeImage, FileId: "file-1"}, resp.Data.ObjectString())
+
+	_, err = NewFile("token").RetrieveRequest().Do(context.Background(), "")
+	requireValidationError(t, err)
+}
@@ -0,0 +1,252 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/chenmingyong0423/go-coze/internal/cozetest"
+	"github.com/stretchr/testify/require"
+)
+
+// pattern 是按 251 循环的字节序列，长度为 251 的整数倍。
+var pattern = func() []byte {
+	b := make([]byte, 251*256)
+	for i := range b {
+		b[i] = byte(i % 251)
+	}
+	return b
+}()
+
+// patternReader 生成 n 个按固定规律排列的字节，不实现 Len 等方法，因此上传时大小未知。
+type patternReader struct {
+	n   int64
+	off int64
+}
+
+func (p *patternReader) Read(b []byte) (int, error) {
+	if p.off >= p.n {
+		return 0, io.EOF
+	}
+	if int64(len(b)) > p.n-p.off {
+		b = b[:p.n-p.off]
+	}
+	n := copy(b, pattern[p.off%251:])
+	p.off += int64(n)
+	return n, nil
+}
+
+// received 记录本地服务以流的方式收到的文件。
+type received struct {
+	mu            sync.Mutex
+	contentLength int64
+	bytes         int64
+	crc           uint32
+}
+
+// newStreamingServer 启动一个以流的方式读取上传文件的本地服务，不会将文件保存到内存或磁盘。
+func newStreamingServer(t *testing.T) *received {
+	got := &received{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/files/upload", func(w http.ResponseWriter, r *http.Request) {
+		mr, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		part, err := mr.NextPart()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h := crc32.NewIEEE()
+		n, err := io.Copy(h, part)
+		if err != nil {
+			return
+		}
+		got.mu.Lock()
+		got.contentLength, got.bytes, got.crc = r.ContentLength, n, h.Sum32()
+		got.mu.Unlock()
+		cozetest.WriteData(w, response.File{Id: "file-1", Bytes: n, FileName: part.FileName()})
+	})
+	cozetest.NewServer(t, mux)
+	return got
+}
+
+func patternCRC(t *testing.T, n int64) uint32 {
+	h := crc32.NewIEEE()
+	_, err := io.Copy(h, &patternReader{n: n})
+	require.NoError(t, err)
+	return h.Sum32()
+}
+
+func TestUploadRequest_Stream(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping large upload in short mode")
+	}
+	const size = 300 << 20
+
+	t.Run("unknown size", func(t *testing.T) {
+		got := newStreamingServer(t)
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+
+		// 回调在写入请求体的 goroutine 中执行，只记录数据，Do 返回后再断言。
+		var sents, totals []int64
+		resp, err := NewFile("token").UploadRequest().
+			WithReader("audio.mp3", &patternReader{n: size}).
+			WithProgress(func(sent, total int64) {
+				sents = append(sents, sent)
+				totals = append(totals, total)
+			}).
+			Do(context.Background())
+		require.NoError(t, err)
+
+		runtime.ReadMemStats(&after)
+		require.Equal(t, int64(size), resp.Data.Bytes)
+		require.True(t, len(sents) > 1)
+		for i, total := range totals {
+			require.Equal(t, int64(-1), total)
+			if i > 0 {
+				require.True(t, sents[i] > sents[i-1])
+			}
+		}
+		require.Equal(t, int64(size), sents[len(sents)-1])
+		require.Equal(t, int64(-1), got.contentLength)
+		require.Equal(t, patternCRC(t, size), got.crc)
+		// 客户端和本地服务在同一个进程中，堆内存的增长远小于文件大小说明请求体没有被整体缓存
+		require.True(t, after.HeapInuse < before.HeapInuse+size/4, "heap grew from %d to %d", before.HeapInuse, after.HeapInuse)
+	})
+
+	t.Run("known size", func(t *testing.T) {
+		got := newStreamingServer(t)
+		path := filepath.Join(t.TempDir(), "large.pdf")
+		f, err := os.Create(path)
+		require.NoError(t, err)
+		require.NoError(t, f.Truncate(size))
+		require.NoError(t, f.Close())
+
+		var lastTotal int64
+		resp, err := NewFile("token").UploadRequest().WithPath(path).
+			WithProgress(func(sent, total int64) { lastTotal = total }).
+			Do(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, int64(size), resp.Data.Bytes)
+		require.Equal(t, int64(size), lastTotal)
+		require.True(t, got.contentLength > size)
+	})
+}
+
+func TestUploadRequest_Cancel(t *testing.T) {
+	newStreamingServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var sent int64
+	_, err := NewFile("token").UploadRequest().
+		WithReader("audio.mp3", &patternReader{n: 100 << 20}).
+		WithProgress(func(n, total int64) {
+			sent = n
+			if n >= 1<<20 {
+				cancel()
+			}
+		}).
+		Do(ctx)
+	require.True(t, errors.Is(err, context.Canceled), err)
+	require.True(t, sent < 100<<20)
+}
+
+func TestUploadRequest_TooLarge(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping large upload in short mode")
+	}
+	newStreamingServer(t)
+	_, err := NewFile("token").UploadRequest().
+		WithReader("audio.mp3", io.LimitReader(&patternReader{n: MaxUploadSize + 1}, MaxUploadSize+1)).
+		Do(context.Background())
+	require.Equal(t, ErrFileTooLarge, err)
+
+	_, err = NewFile("token").UploadRequest().
+		WithReader("large.bin", io.NewSectionReader(nil, 0, MaxUploadSize+1)).
+		Do(context.Background())
+	require.Equal(t, ErrFileTooLarge, err)
+}
+
+func TestUploadRequest_PartiallyRead(t *testing.T) {
+	got := newStreamingServer(t)
+	path := filepath.Join(t.TempDir(), "a.bin")
+	require.NoError(t, os.WriteFile(path, pattern[:1000], 0o600))
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = f.Seek(400, io.SeekStart)
+	require.NoError(t, err)
+
+	// 已读取的部分不会被上传，请求的大小只包含剩余的字节。
+	var lastTotal int64
+	resp, err := NewFile("token").UploadRequest().WithReader("a.bin", f).
+		WithProgress(func(sent, total int64) { lastTotal = total }).
+		Do(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(600), resp.Data.Bytes)
+	require.Equal(t, int64(600), lastTotal)
+	require.Equal(t, crc32.ChecksumIEEE(pattern[400:1000]), got.crc)
+
+	section := io.NewSectionReader(bytes.NewReader(pattern), 0, 1000)
+	_, err = section.Seek(900, io.SeekStart)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), size(section))
+}
+
+func TestUploadRequest_Empty(t *testing.T) {
+	newStreamingServer(t)
+	path := filepath.Join(t.TempDir(), "empty.txt")
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	testCases := []struct {
+		name string
+		req  *UploadRequest
+	}{
+		{name: "empty path", req: NewFile("token").UploadRequest().WithPath(path)},
+		{name: "empty file", req: NewFile("token").UploadRequest().WithReader("empty.txt", f)},
+		{name: "fully read reader", req: NewFile("token").UploadRequest().WithReader("a.txt", strings.NewReader(""))},
+		{name: "unknown size", req: NewFile("token").UploadRequest().WithReader("a.txt", &patternReader{})},
+		{name: "skip validation", req: NewFile("token").UploadRequest().WithReader("a.txt", bytes.NewReader(nil)).WithSkipValidation(true)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.req.Do(context.Background())
+			var validationErr *request.ValidationError
+			require.True(t, errors.As(err, &validationErr), err)
+		})
+	}
+}
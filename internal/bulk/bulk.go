@@ -0,0 +1,43 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bulk 提供批量处理多个会话时使用的错误类型。
+package bulk
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Error 表示批量处理时部分会话失败。
+type Error struct {
+	// 失败的操作，例如 delete、sync，用于生成错误信息。
+	Op string
+	// 失败的会话 ID 及对应的错误。
+	Failed map[string]error
+}
+
+func (e *Error) Error() string {
+	ids := make([]string, 0, len(e.Failed))
+	for id := range e.Failed {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	msgs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", id, e.Failed[id]))
+	}
+	return fmt.Sprintf("failed to %s %d conversation(s): %s", e.Op, len(ids), strings.Join(msgs, "; "))
+}
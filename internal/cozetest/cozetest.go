@@ -0,0 +1,67 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cozetest 提供用于单元测试的本地 Coze API 替身服务。
+package cozetest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// NewServer 启动一个本地 HTTP 服务，并在测试期间将所有发往 Coze API 的请求转发到该服务。
+// 由于替换的是全局的 http.DefaultTransport，使用该函数的测试不能并行执行。
+func NewServer(t *testing.T, handler http.Handler) *httptest.Server {
+	server := httptest.NewServer(handler)
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	original := http.DefaultTransport
+	http.DefaultTransport = &rewriteTransport{target: target, transport: server.Client().Transport}
+	t.Cleanup(func() {
+		http.DefaultTransport = original
+		server.Close()
+	})
+	return server
+}
+
+type rewriteTransport struct {
+	target    *url.URL
+	transport http.RoundTripper
+}
+
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	req.Host = t.target.Host
+	return t.transport.RoundTrip(req)
+}
+
+// WriteJSON 将 v 序列化为 JSON 并写入响应。
+func WriteJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = jsoniter.NewEncoder(w).Encode(v)
+}
+
+// WriteData 写入 code 为 0 的 Coze API 响应。
+func WriteData(w http.ResponseWriter, data any) {
+	WriteJSON(w, map[string]any{"code": 0, "msg": "", "data": data})
+}
@@ -0,0 +1,60 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jsonfile 读写保存在单个 JSON 文件中的数据。
+package jsonfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Read 将文件内容解析到 v 中，文件不存在或为空时不修改 v。
+func Read(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return jsoniter.Unmarshal(data, v)
+}
+
+// Write 将 v 序列化后写入文件，通过临时文件加重命名的方式保证文件完整，但不提供跨进程的锁。
+func Write(path string, v any) error {
+	data, err := jsoniter.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
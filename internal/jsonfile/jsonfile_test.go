@@ -0,0 +1,48 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+
+	// 文件不存在或为空时不修改 v。
+	got := map[string]int{"keep": 1}
+	require.NoError(t, Read(path, &got))
+	require.Equal(t, map[string]int{"keep": 1}, got)
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+	require.NoError(t, Read(path, &got))
+	require.Equal(t, map[string]int{"keep": 1}, got)
+
+	require.NoError(t, Write(path, map[string]int{"a": 1, "b": 2}))
+	got = map[string]int{}
+	require.NoError(t, Read(path, &got))
+	require.Equal(t, map[string]int{"a": 1, "b": 2}, got)
+
+	// 写入完成后不残留临时文件。
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+	require.Error(t, Read(path, &got))
+}
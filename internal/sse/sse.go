@@ -0,0 +1,94 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sse 解析 Coze 流式接口返回的 Server-Sent Events。
+package sse
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Event 是一条服务端推送的事件。
+type Event struct {
+	Id    string
+	Event string
+	Data  string
+	// Plain 表示这一行不是 SSE 格式，Data 为原始内容。
+	// 流式接口在请求出错时可能直接返回 JSON 格式的错误信息。
+	Plain bool
+}
+
+// Reader 从流中逐条读取事件。
+type Reader struct {
+	scanner *bufio.Scanner
+}
+
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	// 单条消息可能较大，放宽单行的长度限制
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	return &Reader{scanner: scanner}
+}
+
+// Next 返回下一条事件，流结束时返回 io.EOF。
+// 事件以空行结束，流在最后一条事件的空行之前结束时仍会返回该事件。
+func (r *Reader) Next() (*Event, error) {
+	var (
+		ev      Event
+		data    []string
+		hasData bool
+		started bool
+	)
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+		if line == "" {
+			if started {
+				ev.Data = strings.Join(data, "\n")
+				return &ev, nil
+			}
+			continue
+		}
+		field, value, ok := strings.Cut(line, ":")
+		switch {
+		case ok && field == "event":
+			ev.Event = strings.TrimSpace(value)
+		case ok && field == "data":
+			data = append(data, strings.TrimSpace(value))
+			hasData = true
+		case ok && field == "id":
+			ev.Id = strings.TrimSpace(value)
+		case ok && field == "retry":
+		case strings.HasPrefix(line, ":"):
+			// 注释行
+			continue
+		default:
+			if started {
+				// 忽略事件中无法识别的字段
+				continue
+			}
+			return &Event{Data: line, Plain: true}, nil
+		}
+		started = true
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	if started && (hasData || ev.Event != "") {
+		ev.Data = strings.Join(data, "\n")
+		return &ev, nil
+	}
+	return nil, io.EOF
+}
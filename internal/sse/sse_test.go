@@ -0,0 +1,85 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sse
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(t *testing.T, s string) []Event {
+	r := NewReader(strings.NewReader(s))
+	var events []Event
+	for {
+		ev, err := r.Next()
+		if err == io.EOF {
+			return events
+		}
+		require.NoError(t, err)
+		events = append(events, *ev)
+	}
+}
+
+func TestReader_Next(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  []Event
+	}{
+		{
+			name:  "chat stream",
+			input: "event:conversation.chat.created\ndata:{\"id\":\"1\"}\n\nevent:done\ndata:\"[DONE]\"\n\n",
+			want: []Event{
+				{Event: "conversation.chat.created", Data: `{"id":"1"}`},
+				{Event: "done", Data: `"[DONE]"`},
+			},
+		},
+		{
+			name:  "workflow stream with ids and comments",
+			input: ": ping\nid: 0\nevent: Message\ndata: {\"content\":\"a\"}\n\nid: 1\nretry: 100\nevent: Done\ndata: {}\n\n",
+			want: []Event{
+				{Id: "0", Event: "Message", Data: `{"content":"a"}`},
+				{Id: "1", Event: "Done", Data: `{}`},
+			},
+		},
+		{
+			name:  "multi-line data",
+			input: "event: Message\ndata: line1\ndata: line2\n\n",
+			want:  []Event{{Event: "Message", Data: "line1\nline2"}},
+		},
+		{
+			name:  "missing trailing blank line",
+			input: "event: Done\ndata: {}",
+			want:  []Event{{Event: "Done", Data: "{}"}},
+		},
+		{
+			name:  "plain json error",
+			input: "{\"code\":4000,\"msg\":\"invalid\"}\n",
+			want:  []Event{{Data: `{"code":4000,"msg":"invalid"}`, Plain: true}},
+		},
+		{
+			name:  "empty",
+			input: "\n\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, readAll(t, tc.input))
+		})
+	}
+}
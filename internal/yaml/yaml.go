@@ -0,0 +1,528 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package yaml 解析 YAML 的一个常用子集，结果与 JSON 解码到 any 的结构相同，可以再转换为 JSON 解码到结构体中。
+//
+// 支持块格式的映射和序列、单行的流格式（[a, b]、{k: v}）、单引号和双引号字符串、字面量（|）和折叠（>）块标量以及注释。
+// 不支持锚点、别名、标签、多文档以及跨行的普通标量和流格式，遇到时返回错误。
+package yaml
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	intPattern   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	floatPattern = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+)
+
+// Unmarshal 解析 YAML 文档。映射解析为 map[string]any，序列解析为 []any，
+// 标量解析为 string、int64、float64、bool 或 nil。空文档返回 nil。
+func Unmarshal(data []byte) (any, error) {
+	text := strings.TrimPrefix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\ufeff")
+	p := &parser{lines: strings.Split(text, "\n")}
+
+	if l, ok := p.peek(); ok && l.content == "---" && l.indent == 0 {
+		p.pos++
+	}
+	var (
+		v   any
+		err error
+	)
+	if l, ok := p.peek(); ok && !l.isEnd() {
+		if v, err = p.parseBlock(l.indent); err != nil {
+			return nil, err
+		}
+	}
+	if l, ok := p.peek(); ok && l.isEnd() {
+		p.pos++
+	}
+	if l, ok := p.peek(); ok {
+		return nil, l.errorf("unexpected content %q", l.content)
+	}
+	return v, nil
+}
+
+type parser struct {
+	lines []string
+	pos   int
+}
+
+// line 是去掉缩进和注释后的一行。
+type line struct {
+	no      int
+	indent  int
+	content string
+	// 缩进之后是否紧跟着制表符，YAML 不允许使用制表符缩进。
+	tab bool
+}
+
+func (l line) errorf(format string, args ...any) error {
+	return fmt.Errorf("yaml: line %d: %s", l.no, fmt.Sprintf(format, args...))
+}
+
+// isEnd 判断该行是否为文档的分隔或结束标记。
+func (l line) isEnd() bool {
+	return l.indent == 0 && (l.content == "..." || l.content == "---")
+}
+
+// isSeqItem 判断该行是否为块序列的元素。
+func (l line) isSeqItem() bool {
+	return l.content == "-" || strings.HasPrefix(l.content, "- ")
+}
+
+// peek 跳过空行和注释行，返回下一行但不消费它。
+func (p *parser) peek() (line, bool) {
+	for ; p.pos < len(p.lines); p.pos++ {
+		raw := p.lines[p.pos]
+		trimmed := strings.TrimLeft(raw, " ")
+		content := strings.TrimSpace(stripComment(trimmed))
+		if content == "" {
+			continue
+		}
+		return line{no: p.pos + 1, indent: len(raw) - len(trimmed), content: content, tab: trimmed[0] == '\t'}, true
+	}
+	return line{}, false
+}
+
+// parseBlock 解析缩进为 indent 的块：序列、映射或单个标量。
+func (p *parser) parseBlock(indent int) (any, error) {
+	l, _ := p.peek()
+	if l.tab {
+		return nil, l.errorf("tabs are not allowed for indentation")
+	}
+	if l.isSeqItem() {
+		return p.parseSeq(indent)
+	}
+	if _, _, ok, err := splitKey(l); err != nil {
+		return nil, err
+	} else if ok {
+		return p.parseMap(indent)
+	}
+	p.pos++
+	return p.parseValue(l, l.content, indent-1)
+}
+
+func (p *parser) parseMap(indent int) (map[string]any, error) {
+	m := make(map[string]any)
+	for {
+		l, ok := p.peek()
+		if !ok || l.indent < indent || l.isEnd() {
+			return m, nil
+		}
+		if l.indent > indent || l.tab {
+			return nil, l.errorf("unexpected indentation")
+		}
+		key, rest, ok, err := splitKey(l)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, l.errorf("expected a mapping key, got %q", l.content)
+		}
+		if _, dup := m[key]; dup {
+			return nil, l.errorf("duplicate key %q", key)
+		}
+		p.pos++
+		if m[key], err = p.parseValue(l, rest, indent); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseSeq(indent int) ([]any, error) {
+	s := make([]any, 0)
+	for {
+		l, ok := p.peek()
+		// 遇到同一缩进的其他内容时结束，由调用方继续处理。
+		if !ok || l.indent < indent || l.isEnd() || (l.indent == indent && !l.isSeqItem()) {
+			return s, nil
+		}
+		if l.indent > indent || l.tab {
+			return nil, l.errorf("unexpected indentation")
+		}
+		raw := strings.TrimLeft(p.lines[p.pos][l.indent+1:], " ")
+		itemIndent := len(p.lines[p.pos]) - len(raw)
+		item := line{no: l.no, indent: itemIndent, content: strings.TrimSpace(stripComment(raw))}
+
+		_, _, isMap, err := splitKey(item)
+		if err != nil {
+			return nil, err
+		}
+		if isMap || item.isSeqItem() {
+			// 元素本身是映射或序列时，将 "- " 替换为空格，按元素内容所在的缩进继续解析。
+			p.lines[p.pos] = strings.Repeat(" ", itemIndent) + raw
+			v, err := p.parseBlock(itemIndent)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+			continue
+		}
+		p.pos++
+		v, err := p.parseValue(l, item.content, indent)
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, v)
+	}
+}
+
+// parseValue 解析键或序列元素之后的值，rest 为同一行中剩余的内容，parentIndent 为键或 "-" 所在的缩进。
+func (p *parser) parseValue(l line, rest string, parentIndent int) (any, error) {
+	switch {
+	case rest == "":
+		next, ok := p.peek()
+		if !ok || next.isEnd() {
+			return nil, nil
+		}
+		if next.indent > parentIndent {
+			return p.parseBlock(next.indent)
+		}
+		// 映射的值可以是与键缩进相同的序列。
+		if next.indent == parentIndent && next.isSeqItem() && !l.isSeqItem() {
+			return p.parseSeq(next.indent)
+		}
+		return nil, nil
+	case rest[0] == '|' || rest[0] == '>':
+		return p.parseBlockScalar(l, rest, parentIndent)
+	case rest[0] == '[' || rest[0] == '{':
+		f := &flowParser{line: l, text: rest}
+		v, err := f.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if f.skipSpace(); f.pos < len(f.text) {
+			return nil, l.errorf("unexpected %q after flow collection", f.text[f.pos:])
+		}
+		return v, nil
+	case rest[0] == '"' || rest[0] == '\'':
+		s, n, err := parseQuoted(l, rest)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(rest[n:]) != "" {
+			return nil, l.errorf("unexpected %q after quoted string", rest[n:])
+		}
+		return s, nil
+	default:
+		return parsePlain(l, rest)
+	}
+}
+
+// parseBlockScalar 解析 | 和 > 开头的块标量，支持 - 和 + 两种处理末尾换行的方式。
+func (p *parser) parseBlockScalar(l line, header string, parentIndent int) (string, error) {
+	folded, chomp := header[0] == '>', header[1:]
+	if chomp != "" && chomp != "-" && chomp != "+" {
+		return "", l.errorf("unsupported block scalar header %q", header)
+	}
+
+	var lines []string
+	blockIndent := -1
+	for ; p.pos < len(p.lines); p.pos++ {
+		raw := p.lines[p.pos]
+		trimmed := strings.TrimLeft(raw, " ")
+		if strings.TrimSpace(trimmed) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		indent := len(raw) - len(trimmed)
+		if blockIndent < 0 {
+			if indent <= parentIndent {
+				break
+			}
+			blockIndent = indent
+		}
+		if indent < blockIndent {
+			break
+		}
+		lines = append(lines, raw[blockIndent:])
+	}
+
+	end := len(lines)
+	for end > 0 && lines[end-1] == "" {
+		end--
+	}
+	trailing := len(lines) - end
+	lines = lines[:end]
+	if len(lines) == 0 {
+		return "", nil
+	}
+
+	var b strings.Builder
+	for i, s := range lines {
+		if i > 0 {
+			// 折叠块标量中相邻的普通文本行以空格连接，空行表示换行，缩进更多的行保留原样。
+			sep, prev := "\n", lines[i-1]
+			if folded && prev != "" && prev[0] != ' ' {
+				if s == "" {
+					sep = ""
+				} else if s[0] != ' ' {
+					sep = " "
+				}
+			}
+			b.WriteString(sep)
+		}
+		b.WriteString(s)
+	}
+	switch chomp {
+	case "":
+		b.WriteByte('\n')
+	case "+":
+		b.WriteString(strings.Repeat("\n", trailing+1))
+	}
+	return b.String(), nil
+}
+
+// splitKey 将 "key: value" 拆分为键和值，该行不是映射的键值对时 ok 为 false。
+func splitKey(l line) (key, rest string, ok bool, err error) {
+	content := l.content
+	if content == "" || l.isSeqItem() {
+		return "", "", false, nil
+	}
+	if content[0] == '"' || content[0] == '\'' {
+		s, n, err := parseQuoted(l, content)
+		if err != nil {
+			return "", "", false, err
+		}
+		after := content[n:]
+		if after == ":" || strings.HasPrefix(after, ": ") {
+			return s, strings.TrimSpace(after[1:]), true, nil
+		}
+		return "", "", false, nil
+	}
+	if strings.IndexByte("[{?&*!|>%@`", content[0]) >= 0 {
+		return "", "", false, nil
+	}
+	i := strings.Index(content, ": ")
+	if i < 0 {
+		if !strings.HasSuffix(content, ":") {
+			return "", "", false, nil
+		}
+		i = len(content) - 1
+	}
+	return strings.TrimSpace(content[:i]), strings.TrimSpace(content[i+1:]), true, nil
+}
+
+// stripComment 去掉行尾的注释，引号中的 # 不是注释。
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote == '\'' && c == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" \t:-[{,", s[i-1]) >= 0):
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// parseQuoted 解析 s 开头的单引号或双引号字符串，返回字符串以及消费的字节数。
+func parseQuoted(l line, s string) (string, int, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if quote == '\'' {
+			if c != '\'' {
+				b.WriteByte(c)
+			} else if i+1 < len(s) && s[i+1] == '\'' {
+				b.WriteByte('\'')
+				i++
+			} else {
+				return b.String(), i + 1, nil
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			return b.String(), i + 1, nil
+		case '\\':
+			if i+1 >= len(s) {
+				return "", 0, l.errorf("unterminated escape sequence")
+			}
+			switch s[i+1] {
+			case '0':
+				b.WriteByte(0)
+			case 'e':
+				b.WriteByte(0x1b)
+			case '/', ' ', '\t':
+				b.WriteByte(s[i+1])
+			default:
+				r, _, tail, err := strconv.UnquoteChar(s[i:], '"')
+				if err != nil {
+					return "", 0, l.errorf("invalid escape sequence in %s", s)
+				}
+				b.WriteRune(r)
+				i = len(s) - len(tail) - 1
+				continue
+			}
+			i++
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, l.errorf("unterminated quoted string %s", s)
+}
+
+// parsePlain 解析普通标量，按内容识别为 nil、bool、整数、浮点数或字符串。
+func parsePlain(l line, s string) (any, error) {
+	if strings.IndexByte("&*!%@`", s[0]) >= 0 {
+		return nil, l.errorf("unsupported syntax %q", s)
+	}
+	switch s {
+	case "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	if intPattern.MatchString(s) {
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n, nil
+		}
+	}
+	if floatPattern.MatchString(s) {
+		if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(f, 0) {
+			return f, nil
+		}
+	}
+	return s, nil
+}
+
+// flowParser 解析单行的流格式集合。
+type flowParser struct {
+	line line
+	text string
+	pos  int
+}
+
+func (f *flowParser) skipSpace() {
+	for f.pos < len(f.text) && f.text[f.pos] == ' ' {
+		f.pos++
+	}
+}
+
+func (f *flowParser) parseValue() (any, error) {
+	f.skipSpace()
+	if f.pos >= len(f.text) {
+		return nil, f.line.errorf("unterminated flow collection")
+	}
+	switch f.text[f.pos] {
+	case '[':
+		return f.parseSeq()
+	case '{':
+		return f.parseMap()
+	case '"', '\'':
+		s, n, err := parseQuoted(f.line, f.text[f.pos:])
+		if err != nil {
+			return nil, err
+		}
+		f.pos += n
+		return s, nil
+	}
+	start := f.pos
+	for f.pos < len(f.text) && strings.IndexByte(",]}", f.text[f.pos]) < 0 && !f.atColon() {
+		f.pos++
+	}
+	s := strings.TrimSpace(f.text[start:f.pos])
+	if s == "" {
+		return nil, f.line.errorf("missing value in flow collection")
+	}
+	return parsePlain(f.line, s)
+}
+
+// atColon 判断当前位置是否为流格式映射中键之后的冒号。
+func (f *flowParser) atColon() bool {
+	return f.text[f.pos] == ':' && (f.pos+1 == len(f.text) || strings.IndexByte(" ,]}", f.text[f.pos+1]) >= 0)
+}
+
+func (f *flowParser) parseSeq() ([]any, error) {
+	f.pos++
+	s := make([]any, 0)
+	for {
+		if f.skipSpace(); f.pos < len(f.text) && f.text[f.pos] == ']' {
+			f.pos++
+			return s, nil
+		}
+		v, err := f.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, v)
+		if err = f.next(']'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (f *flowParser) parseMap() (map[string]any, error) {
+	f.pos++
+	m := make(map[string]any)
+	for {
+		if f.skipSpace(); f.pos < len(f.text) && f.text[f.pos] == '}' {
+			f.pos++
+			return m, nil
+		}
+		k, err := f.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		key := fmt.Sprint(k)
+		if f.skipSpace(); f.pos >= len(f.text) || f.text[f.pos] != ':' {
+			return nil, f.line.errorf("expected ':' after flow mapping key %q", key)
+		}
+		f.pos++
+		if _, dup := m[key]; dup {
+			return nil, f.line.errorf("duplicate key %q", key)
+		}
+		if m[key], err = f.parseValue(); err != nil {
+			return nil, err
+		}
+		if err = f.next('}'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// next 消费元素之间的逗号，遇到结束符时不消费，留给调用方处理。
+func (f *flowParser) next(end byte) error {
+	f.skipSpace()
+	if f.pos < len(f.text) && f.text[f.pos] == ',' {
+		f.pos++
+		return nil
+	}
+	if f.pos < len(f.text) && f.text[f.pos] == end {
+		return nil
+	}
+	if f.pos >= len(f.text) {
+		return f.line.errorf("unterminated flow collection")
+	}
+	return f.line.errorf("unexpected %q in flow collection", f.text[f.pos:])
+}
@@ -0,0 +1,103 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yaml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshal(t *testing.T) {
+	testCases := []struct {
+		name string
+		data string
+		want any
+	}{
+		{name: "empty", data: "# 只有注释\n\n", want: nil},
+		{
+			name: "mapping",
+			data: "---\nname: 天气助手 # 注释\nid: '7379462189365198898'\ncount: 3\nratio: 0.7\nenabled: true\nnone: ~\nurl: https://example.com/a#b\n",
+			want: map[string]any{"name": "天气助手", "id": "7379462189365198898", "count": int64(3), "ratio": 0.7,
+				"enabled": true, "none": nil, "url": "https://example.com/a#b"},
+		},
+		{
+			name: "nested mapping",
+			data: "model:\n  model_id: m1\n  top_p: 1\n\n  # 注释\n  temperature: .5\nknowledge: {dataset_ids: [d1, \"d2\"], auto_call: true}\n",
+			want: map[string]any{
+				"model":     map[string]any{"model_id": "m1", "top_p": int64(1), "temperature": 0.5},
+				"knowledge": map[string]any{"dataset_ids": []any{"d1", "d2"}, "auto_call": true},
+			},
+		},
+		{
+			name: "sequences",
+			data: "questions:\n  - 今天天气怎么样？\n  - \"明天呢: 北京\"\nplugins:\n- plugin_id: p1\n  api_id: a1\n-   plugin_id: p2\n    api_id: a2\nempty: []\nnested:\n  - - 1\n    - 2\n  -\n    - 3\n",
+			want: map[string]any{
+				"questions": []any{"今天天气怎么样？", "明天呢: 北京"},
+				"plugins":   []any{map[string]any{"plugin_id": "p1", "api_id": "a1"}, map[string]any{"plugin_id": "p2", "api_id": "a2"}},
+				"empty":     []any{},
+				"nested":    []any{[]any{int64(1), int64(2)}, []any{int64(3)}},
+			},
+		},
+		{
+			name: "quoted strings",
+			data: "single: 'it''s # not a comment'\ndouble: \"a\\tb\\n\\u4f60\\\"\"\n\"quoted key\": it's\n",
+			want: map[string]any{"single": "it's # not a comment", "double": "a\tb\n你\"", "quoted key": "it's"},
+		},
+		{
+			name: "literal block scalar",
+			data: "prompt: |\n  # 角色\n  你是天气助手。\n\n    - 缩进保留\nkeep: |+\n  a\n\nstrip: |-\n  a\n  b\n\n",
+			want: map[string]any{"prompt": "# 角色\n你是天气助手。\n\n  - 缩进保留\n", "keep": "a\n\n", "strip": "a\nb"},
+		},
+		{
+			name: "folded block scalar",
+			data: "prologue: >\n  你好，\n  我是天气助手。\n\n  有什么可以帮你？\nlast: >-\n  a\n  b\n",
+			want: map[string]any{"prologue": "你好， 我是天气助手。\n有什么可以帮你？\n", "last": "a b"},
+		},
+		{name: "sequence document", data: "- a\n- b: 1\n", want: []any{"a", map[string]any{"b": int64(1)}}},
+		{name: "scalar document", data: "hello\n...\n", want: "hello"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Unmarshal([]byte(tc.data))
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	testCases := []struct {
+		name string
+		data string
+	}{
+		{name: "tab indentation", data: "model:\n\tmodel_id: m1\n"},
+		{name: "bad indentation", data: "a: 1\n  b: 2\n"},
+		{name: "duplicate key", data: "a: 1\na: 2\n"},
+		{name: "unterminated quote", data: "a: \"b\n"},
+		{name: "unterminated flow", data: "a: [b, c\n"},
+		{name: "multi-line flow", data: "a: [\n  b]\n"},
+		{name: "anchor", data: "a: &x 1\n"},
+		{name: "alias", data: "a: *x\n"},
+		{name: "multiple documents", data: "a: 1\n---\nb: 2\n"},
+		{name: "mixed content", data: "a: 1\n- b\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Unmarshal([]byte(tc.data))
+			require.Error(t, err)
+		})
+	}
+}
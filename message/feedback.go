@@ -0,0 +1,183 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/chenmingyong0423/go-coze/common/response"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// FeedbackType 表示用户对消息的评价类型。
+type FeedbackType string
+
+const (
+	// FeedbackTypeLike 点赞。
+	FeedbackTypeLike FeedbackType = "like"
+	// FeedbackTypeUnlike 点踩。
+	FeedbackTypeUnlike FeedbackType = "unlike"
+)
+
+// FeedbackRequest 提交用户对 Bot 回复的评价，同一条消息重复提交时会覆盖之前的评价。
+type FeedbackRequest struct {
+	timeout   time.Duration
+	message   *Message
+	messageId string
+	// 是否跳过发送请求前的参数校验。
+	skipValidation bool
+
+	// The type of the feedback.
+	// 评价类型。
+	FeedbackType FeedbackType `json:"feedback_type"`
+	// Optional: The reasons of the feedback, only for unlike.
+	// 可选的：点踩的原因标签，仅在评价类型为 unlike 时生效。
+	ReasonTypes []string `json:"reason_types,omitempty"`
+	// Optional: The free-text comment of the feedback.
+	// 可选的：评价的文字说明。
+	Comment string `json:"comment,omitempty"`
+}
+
+func (c *FeedbackRequest) WithTimeout(timeout time.Duration) *FeedbackRequest {
+	c.timeout = timeout
+	return c
+}
+
+// WithSkipValidation 设置是否跳过 Do 发送请求前的参数校验。
+func (c *FeedbackRequest) WithSkipValidation(skip bool) *FeedbackRequest {
+	c.skipValidation = skip
+	return c
+}
+
+func (c *FeedbackRequest) WithFeedbackType(feedbackType FeedbackType) *FeedbackRequest {
+	c.FeedbackType = feedbackType
+	return c
+}
+
+func (c *FeedbackRequest) WithReasonTypes(reasonTypes ...string) *FeedbackRequest {
+	c.ReasonTypes = append(c.ReasonTypes, reasonTypes...)
+	return c
+}
+
+func (c *FeedbackRequest) WithComment(comment string) *FeedbackRequest {
+	c.Comment = comment
+	return c
+}
+
+// Validate 校验请求参数，包括 conversation_id、message_id 必填、评价类型的取值以及原因标签只能用于点踩。
+func (c *FeedbackRequest) Validate() error {
+	if err := request.ValidateRequired("conversation_id", c.message.conversationId); err != nil {
+		return err
+	}
+	if err := request.ValidateRequired("message_id", c.messageId); err != nil {
+		return err
+	}
+	switch c.FeedbackType {
+	case FeedbackTypeLike, FeedbackTypeUnlike:
+	case "":
+		return request.NewValidationError("feedback_type", "is required")
+	default:
+		return request.NewValidationError("feedback_type", fmt.Sprintf("unsupported feedback type %q", c.FeedbackType))
+	}
+	if len(c.ReasonTypes) > 0 && c.FeedbackType != FeedbackTypeUnlike {
+		return request.NewValidationError("reason_types", "only allowed when feedback_type is unlike")
+	}
+	return nil
+}
+
+func (c *FeedbackRequest) Do(ctx context.Context) (*response.BaseResponse, error) {
+	if !c.skipValidation {
+		if err := c.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	body, err := jsoniter.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return doFeedback(ctx, c.message, c.messageId, c.timeout, http.MethodPost, bytes.NewReader(body))
+}
+
+// DeleteFeedbackRequest 删除用户对消息的评价。
+type DeleteFeedbackRequest struct {
+	timeout   time.Duration
+	message   *Message
+	messageId string
+}
+
+func (c *DeleteFeedbackRequest) WithTimeout(timeout time.Duration) *DeleteFeedbackRequest {
+	c.timeout = timeout
+	return c
+}
+
+func (c *DeleteFeedbackRequest) Do(ctx context.Context) (*response.BaseResponse, error) {
+	if err := request.ValidateRequired("conversation_id", c.message.conversationId); err != nil {
+		return nil, err
+	}
+	if err := request.ValidateRequired("message_id", c.messageId); err != nil {
+		return nil, err
+	}
+	return doFeedback(ctx, c.message, c.messageId, c.timeout, http.MethodDelete, nil)
+}
+
+func doFeedback(ctx context.Context, m *Message, messageId string, timeout time.Duration, method string, body io.Reader) (*response.BaseResponse, error) {
+	resp := new(response.BaseResponse)
+
+	u := fmt.Sprintf(feedbackUrl, url.PathEscape(m.conversationId), url.PathEscape(messageId))
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add(HeaderContentType, HeaderApplicationJson)
+	req.Header.Add(HeaderAuthorization, fmt.Sprintf("Bearer %s", m.authorization))
+
+	client := http.DefaultClient
+	if timeout != 0 {
+		client = &http.Client{Timeout: timeout}
+	}
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, &response.HttpErrorResponse{
+			Status:     httpResp.Status,
+			StatusCode: httpResp.StatusCode,
+			Body:       data,
+		}
+	}
+	if err = jsoniter.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
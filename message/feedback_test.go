@@ -0,0 +1,109 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/chenmingyong0423/go-coze/internal/cozetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedbackRequest_Do(t *testing.T) {
+	var (
+		method string
+		body   string
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/conversations/conversation/messages/message/feedback", func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		cozetest.WriteJSON(w, response.BaseResponse{})
+	})
+	cozetest.NewServer(t, mux)
+
+	testCases := []struct {
+		name           string
+		conversationId string
+		messageId      string
+		feedbackType   FeedbackType
+		reasonTypes    []string
+		comment        string
+		wantBody       string
+		wantErr        require.ErrorAssertionFunc
+	}{
+		{
+			name:           "empty messageId",
+			conversationId: "conversation",
+			feedbackType:   FeedbackTypeLike,
+			wantErr:        requireValidationError,
+		},
+		{
+			name:           "empty feedback type",
+			conversationId: "conversation",
+			messageId:      "message",
+			wantErr:        requireValidationError,
+		},
+		{
+			name:           "unsupported feedback type",
+			conversationId: "conversation",
+			messageId:      "message",
+			feedbackType:   "love",
+			wantErr:        requireValidationError,
+		},
+		{
+			name:           "reason types with like",
+			conversationId: "conversation",
+			messageId:      "message",
+			feedbackType:   FeedbackTypeLike,
+			reasonTypes:    []string{"不准确"},
+			wantErr:        requireValidationError,
+		},
+		{
+			name:           "unlike",
+			conversationId: "conversation",
+			messageId:      "message",
+			feedbackType:   FeedbackTypeUnlike,
+			reasonTypes:    []string{"不准确", "答非所问"},
+			comment:        "没有回答我的问题",
+			wantBody:       `{"feedback_type":"unlike","reason_types":["不准确","答非所问"],"comment":"没有回答我的问题"}`,
+			wantErr:        require.NoError,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body = ""
+			resp, err := NewMessage("token", tc.conversationId).FeedbackRequest(tc.messageId).
+				WithFeedbackType(tc.feedbackType).WithReasonTypes(tc.reasonTypes...).WithComment(tc.comment).
+				Do(context.Background())
+			tc.wantErr(t, err)
+			if err == nil {
+				require.Equal(t, 0, resp.Code)
+				require.Equal(t, http.MethodPost, method)
+				require.JSONEq(t, tc.wantBody, body)
+			}
+		})
+	}
+
+	resp, err := NewMessage("token", "conversation").DeleteFeedbackRequest("message").Do(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 0, resp.Code)
+	require.Equal(t, http.MethodDelete, method)
+}
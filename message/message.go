@@ -23,6 +23,7 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/chenmingyong0423/go-coze/common/pager"
 	"github.com/chenmingyong0423/go-coze/common/request"
 
 	"github.com/chenmingyong0423/go-coze/common/response"
@@ -30,17 +31,25 @@ import (
 )
 
 const (
+	OrderDesc = "desc"
+	OrderAsc  = "asc"
+
+	// MaxListLimit 每次查询消息列表时返回的最大消息数量。
+	MaxListLimit = 50
+
 	InternationalCreateUrl   = "https://api.coze.com/v1/conversation/message/create"
 	InternationalListUrl     = "https://api.coze.com/v1/conversation/message/list"
 	InternationalRetrieveUrl = "https://api.coze.com/v1/conversation/message/retrieve"
 	InternationalModifyUrl   = "https://api.coze.com/v1/conversation/message/modify"
 	InternationalDeleteUrl   = "https://api.coze.com/v1/conversation/message/delete"
+	InternationalFeedbackUrl = "https://api.coze.com/v1/conversations/%s/messages/%s/feedback"
 
 	createUrl             = "https://api.coze.cn/v1/conversation/message/create"
 	listUrl               = "https://api.coze.cn/v1/conversation/message/list"
 	retrieveUrl           = "https://api.coze.cn/v1/conversation/message/retrieve"
 	modifyUrl             = "https://api.coze.cn/v1/conversation/message/modify"
 	deleteUrl             = "https://api.coze.cn/v1/conversation/message/delete"
+	feedbackUrl           = "https://api.coze.cn/v1/conversations/%s/messages/%s/feedback"
 	HeaderAuthorization   = "authorization"
 	HeaderContentType     = "Content-Type"
 	HeaderApplicationJson = "application/json"
@@ -75,8 +84,18 @@ func (m *Message) DeleteRequest(messageId string) *DeleteRequest {
 	return &DeleteRequest{message: m, messageId: messageId}
 }
 
+func (m *Message) FeedbackRequest(messageId string) *FeedbackRequest {
+	return &FeedbackRequest{message: m, messageId: messageId}
+}
+
+func (m *Message) DeleteFeedbackRequest(messageId string) *DeleteFeedbackRequest {
+	return &DeleteFeedbackRequest{message: m, messageId: messageId}
+}
+
 type CreateRequest struct {
 	timeout time.Duration
+	// 是否跳过发送请求前的参数校验。
+	skipValidation bool
 
 	message     *Message
 	Role        string         `json:"role"`
@@ -90,6 +109,12 @@ func (c *CreateRequest) WithTimeout(timeout time.Duration) *CreateRequest {
 	return c
 }
 
+// WithSkipValidation 设置是否跳过 Do 发送请求前的参数校验。
+func (c *CreateRequest) WithSkipValidation(skip bool) *CreateRequest {
+	c.skipValidation = skip
+	return c
+}
+
 func (c *CreateRequest) WithRole(role string) *CreateRequest {
 	c.Role = role
 	return c
@@ -97,18 +122,25 @@ func (c *CreateRequest) WithRole(role string) *CreateRequest {
 
 func (c *CreateRequest) WithTextContent(content string) *CreateRequest {
 	c.Content = content
-	c.ContentType = "text"
+	c.ContentType = request.ContentTypeText
 	return c
 }
 
-func (c *CreateRequest) WithObjectStringContent(objectString request.ObjectString) *CreateRequest {
-	marshal, _ := jsoniter.Marshal(objectString)
-	c.Content = string(marshal)
-	c.ContentType = "object_string"
+// WithObjectStringContent 设置多模态消息内容，多个部分会被序列化为 JSON 数组，内容的合法性会在发送请求前校验。
+func (c *CreateRequest) WithObjectStringContent(objectStrings ...request.ObjectString) *CreateRequest {
+	marshal, _ := jsoniter.MarshalToString(objectStrings)
+	c.Content = marshal
+	c.ContentType = request.ContentTypeObjectString
 	return c
 }
 
 func (c *CreateRequest) Do(ctx context.Context) (*response.DataResponse[response.Message], error) {
+	if !c.skipValidation {
+		if err := c.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	params := url.Values{}
 	params.Add("conversation_id", c.message.conversationId)
 
@@ -134,7 +166,7 @@ func (c *CreateRequest) Do(ctx context.Context) (*response.DataResponse[response
 
 	client := http.DefaultClient
 	if c.timeout != 0 {
-		client.Timeout = c.timeout
+		client = &http.Client{Timeout: c.timeout}
 	}
 
 	httpResp, err := client.Do(req)
@@ -162,9 +194,30 @@ func (c *CreateRequest) Do(ctx context.Context) (*response.DataResponse[response
 	return resp, nil
 }
 
+// Validate 校验请求参数，包括 conversation_id、role、content 等必填字段、内容类型以及附加信息的大小限制。
+func (c *CreateRequest) Validate() error {
+	if err := request.ValidateRequired("conversation_id", c.message.conversationId); err != nil {
+		return err
+	}
+	if err := request.ValidateRole("role", c.Role); err != nil {
+		return err
+	}
+	if err := request.ValidateRequired("content", c.Content); err != nil {
+		return err
+	}
+	if err := validateContent(c.ContentType, c.Content); err != nil {
+		return err
+	}
+	return request.ValidateMetaData("meta_data", c.Meta)
+}
+
 type ListRequest struct {
 	timeout time.Duration
 	message *Message
+	// 是否跳过发送请求前的参数校验。
+	skipValidation bool
+	// 只返回该 section 中的消息，在客户端过滤。
+	sectionId string
 
 	Order    string `json:"order,omitempty"`
 	ChatId   string `json:"chat_id,omitempty"`
@@ -178,6 +231,12 @@ func (c *ListRequest) WithTimeout(timeout time.Duration) *ListRequest {
 	return c
 }
 
+// WithSkipValidation 设置是否跳过 Do 发送请求前的参数校验。
+func (c *ListRequest) WithSkipValidation(skip bool) *ListRequest {
+	c.skipValidation = skip
+	return c
+}
+
 func (c *ListRequest) WithOrder(order string) *ListRequest {
 	c.Order = order
 	return c
@@ -198,12 +257,43 @@ func (c *ListRequest) WithAfterId(afterId string) *ListRequest {
 	return c
 }
 
+// WithLimit 设置每页返回的消息数量，取值范围为 0 到 MaxListLimit，0 表示使用服务端的默认值。
 func (c *ListRequest) WithLimit(limit int) *ListRequest {
 	c.Limit = limit
 	return c
 }
 
+// WithSectionId 只返回指定 section 中的消息，用于查看清除上下文后的某一段对话。
+// 过滤在客户端进行，因此单页返回的消息数可能少于 limit，FirstId、LastId 和 HasMore 仍然对应过滤前的结果，可继续用于翻页。
+func (c *ListRequest) WithSectionId(sectionId string) *ListRequest {
+	c.sectionId = sectionId
+	return c
+}
+
+// Validate 校验请求参数，包括 conversation_id 必填、order 取值、limit 范围以及 before_id 与 after_id 不能同时指定。
+func (c *ListRequest) Validate() error {
+	if err := request.ValidateRequired("conversation_id", c.message.conversationId); err != nil {
+		return err
+	}
+	if c.Order != "" && c.Order != OrderDesc && c.Order != OrderAsc {
+		return request.NewValidationError("order", fmt.Sprintf("unsupported order %q", c.Order))
+	}
+	if c.Limit < 0 || c.Limit > MaxListLimit {
+		return request.NewValidationError("limit", fmt.Sprintf("must be between 0 and %d, 0 means the server default", MaxListLimit))
+	}
+	if c.BeforeId != "" && c.AfterId != "" {
+		return request.NewValidationError("before_id", "before_id and after_id cannot be specified at the same time")
+	}
+	return nil
+}
+
 func (c *ListRequest) Do(ctx context.Context) (*ListResponse[[]response.Message], error) {
+	if !c.skipValidation {
+		if err := c.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	params := url.Values{}
 	params.Add("conversation_id", c.message.conversationId)
 
@@ -230,7 +320,7 @@ func (c *ListRequest) Do(ctx context.Context) (*ListResponse[[]response.Message]
 
 	client := http.DefaultClient
 	if c.timeout != 0 {
-		client.Timeout = c.timeout
+		client = &http.Client{Timeout: c.timeout}
 	}
 
 	httpResp, err := client.Do(req)
@@ -254,6 +344,15 @@ func (c *ListRequest) Do(ctx context.Context) (*ListResponse[[]response.Message]
 	if err = jsoniter.Unmarshal(data, &resp); err != nil {
 		return resp, err
 	}
+	if c.sectionId != "" {
+		messages := make([]response.Message, 0, len(resp.Data))
+		for _, m := range resp.Data {
+			if m.SectionId == c.sectionId {
+				messages = append(messages, m)
+			}
+		}
+		resp.Data = messages
+	}
 
 	return resp, nil
 }
@@ -292,7 +391,7 @@ func (c *RetrieveRequest) Do(ctx context.Context) (*response.DataResponse[respon
 
 	client := http.DefaultClient
 	if c.timeout != 0 {
-		client.Timeout = c.timeout
+		client = &http.Client{Timeout: c.timeout}
 	}
 
 	httpResp, err := client.Do(req)
@@ -324,6 +423,8 @@ type ModifyRequest struct {
 	timeout   time.Duration
 	message   *Message
 	messageId string
+	// 是否跳过发送请求前的参数校验。
+	skipValidation bool
 
 	Content     string         `json:"content,omitempty"`
 	ContentType string         `json:"content_type,omitempty"`
@@ -335,20 +436,33 @@ func (c *ModifyRequest) WithTimeout(timeout time.Duration) *ModifyRequest {
 	return c
 }
 
+// WithSkipValidation 设置是否跳过 Do 发送请求前的参数校验。
+func (c *ModifyRequest) WithSkipValidation(skip bool) *ModifyRequest {
+	c.skipValidation = skip
+	return c
+}
+
 func (c *ModifyRequest) WithTextContent(content string) *ModifyRequest {
 	c.Content = content
-	c.ContentType = "text"
+	c.ContentType = request.ContentTypeText
 	return c
 }
 
-func (c *ModifyRequest) WithObjectStringContent(objectString request.ObjectString) *ModifyRequest {
-	marshal, _ := jsoniter.Marshal(objectString)
-	c.Content = string(marshal)
-	c.ContentType = "object_string"
+// WithObjectStringContent 设置多模态消息内容，多个部分会被序列化为 JSON 数组，内容的合法性会在发送请求前校验。
+func (c *ModifyRequest) WithObjectStringContent(objectStrings ...request.ObjectString) *ModifyRequest {
+	marshal, _ := jsoniter.MarshalToString(objectStrings)
+	c.Content = marshal
+	c.ContentType = request.ContentTypeObjectString
 	return c
 }
 
 func (c *ModifyRequest) Do(ctx context.Context) (*ModifyResponse[response.Message], error) {
+	if !c.skipValidation {
+		if err := c.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	params := url.Values{}
 	params.Add("conversation_id", c.message.conversationId)
 	params.Add("message_id", c.messageId)
@@ -375,7 +489,7 @@ func (c *ModifyRequest) Do(ctx context.Context) (*ModifyResponse[response.Messag
 
 	client := http.DefaultClient
 	if c.timeout != 0 {
-		client.Timeout = c.timeout
+		client = &http.Client{Timeout: c.timeout}
 	}
 
 	httpResp, err := client.Do(req)
@@ -403,6 +517,34 @@ func (c *ModifyRequest) Do(ctx context.Context) (*ModifyResponse[response.Messag
 	return resp, nil
 }
 
+// Validate 校验请求参数，包括 conversation_id、message_id 等必填字段、内容类型以及附加信息的大小限制。
+func (c *ModifyRequest) Validate() error {
+	if err := request.ValidateRequired("conversation_id", c.message.conversationId); err != nil {
+		return err
+	}
+	if err := request.ValidateRequired("message_id", c.messageId); err != nil {
+		return err
+	}
+	if c.ContentType != "" {
+		if err := validateContent(c.ContentType, c.Content); err != nil {
+			return err
+		}
+	}
+	return request.ValidateMetaData("meta_data", c.Meta)
+}
+
+func validateContent(contentType, content string) error {
+	if err := request.ValidateContentType("content_type", contentType, request.ContentTypeText, request.ContentTypeObjectString); err != nil {
+		return err
+	}
+	if contentType == request.ContentTypeObjectString {
+		if err := request.ValidateObjectStringContent(content); err != nil {
+			return request.NewValidationError("content", err.Error())
+		}
+	}
+	return nil
+}
+
 type DeleteRequest struct {
 	timeout   time.Duration
 	message   *Message
@@ -436,7 +578,7 @@ func (c *DeleteRequest) Do(ctx context.Context) (*response.DataResponse[response
 
 	client := http.DefaultClient
 	if c.timeout != 0 {
-		client.Timeout = c.timeout
+		client = &http.Client{Timeout: c.timeout}
 	}
 
 	httpResp, err := client.Do(req)
@@ -463,3 +605,50 @@ func (c *DeleteRequest) Do(ctx context.Context) (*response.DataResponse[response
 
 	return resp, nil
 }
+
+// PageDirection 表示遍历消息列表时的翻页方向。
+type PageDirection int
+
+const (
+	// PageForward 向后翻页，以上一页的 last_id 作为 after_id 查询下一页。
+	PageForward PageDirection = iota
+	// PageBackward 向前翻页，以上一页的 first_id 作为 before_id 查询下一页。
+	PageBackward
+)
+
+// Iterator 返回一个按需逐页查询的消息迭代器，起始位置、排序方式等沿用当前请求的参数。
+// 未设置 limit 时每页查询 MaxListLimit 条消息。
+func (c *ListRequest) Iterator(direction PageDirection) *pager.Pager[response.Message] {
+	req := *c
+	if req.Limit == 0 {
+		req.Limit = MaxListLimit
+	}
+	cursor := req.AfterId
+	if direction == PageBackward {
+		cursor = req.BeforeId
+	}
+	return pager.NewCursorPager(cursor, func(ctx context.Context, cursor string) ([]response.Message, string, bool, error) {
+		if direction == PageBackward {
+			req.BeforeId, req.AfterId = cursor, ""
+		} else {
+			req.BeforeId, req.AfterId = "", cursor
+		}
+		resp, err := req.Do(ctx)
+		if err != nil {
+			return nil, "", false, err
+		}
+		if err = resp.Err(); err != nil {
+			return nil, "", false, err
+		}
+		next := resp.LastId
+		if direction == PageBackward {
+			next = resp.FirstId
+		}
+		return resp.Data, next, resp.HasMore, nil
+	})
+}
+
+// All 向后翻页遍历并返回所有消息。
+func (c *ListRequest) All(ctx context.Context) ([]response.Message, error) {
+	return c.Iterator(PageForward).All(ctx)
+}
@@ -17,11 +17,15 @@ package message
 import (
 	"context"
 	"errors"
+	"net/http"
 	"os"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/chenmingyong0423/go-coze/internal/cozetest"
+	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/require"
 
 	"github.com/chenmingyong0423/go-coze/common/response"
@@ -43,9 +47,13 @@ func TestCreateRequest_Do(t *testing.T) {
 		wantErr        require.ErrorAssertionFunc
 	}{
 		{
-			name:          "empty authorization",
-			ctx:           context.Background(),
-			authorization: "",
+			name:           "empty authorization",
+			ctx:            context.Background(),
+			authorization:  "",
+			conversationId: "7414413032111063080",
+			role:           "user",
+			content:        "你好",
+			contentType:    "text",
 			wantErr: func(t require.TestingT, err error, i ...interface{}) {
 				var errResp *response.HttpErrorResponse
 				if errors.As(err, &errResp) {
@@ -60,9 +68,7 @@ func TestCreateRequest_Do(t *testing.T) {
 			ctx:            context.Background(),
 			authorization:  os.Getenv("COZE_TOKEN"),
 			conversationId: "",
-			want: func(t *testing.T, resp *response.DataResponse[response.Message]) {
-				require.Equal(t, 4002, resp.Code)
-			},
+			wantErr:        requireValidationError,
 		},
 		{
 			name:           "empty role",
@@ -72,9 +78,17 @@ func TestCreateRequest_Do(t *testing.T) {
 			role:           "",
 			content:        "你好",
 			contentType:    "text",
-			want: func(t *testing.T, resp *response.DataResponse[response.Message]) {
-				require.Equal(t, 4000, resp.Code)
-			},
+			wantErr:        requireValidationError,
+		},
+		{
+			name:           "invalid object_string content",
+			ctx:            context.Background(),
+			authorization:  os.Getenv("COZE_TOKEN"),
+			conversationId: "7414413032111063080",
+			role:           "user",
+			contentType:    "object_string",
+			objectString:   request.NewObjectStringBuilder().Type("image").Build(),
+			wantErr:        require.Error,
 		},
 		{
 			name:           "success",
@@ -150,9 +164,24 @@ func TestListRequest_Do(t *testing.T) {
 			ctx:            context.Background(),
 			authorization:  os.Getenv("COZE_TOKEN"),
 			conversationId: "",
-			want: func(t *testing.T, resp *ListResponse[[]response.Message]) {
-				require.Equal(t, 4002, resp.Code)
-			},
+			wantErr:        requireValidationError,
+		},
+		{
+			name:           "limit out of range",
+			ctx:            context.Background(),
+			authorization:  os.Getenv("COZE_TOKEN"),
+			conversationId: "7414413032111063080",
+			limit:          MaxListLimit + 1,
+			wantErr:        requireValidationError,
+		},
+		{
+			name:           "both beforeId and afterId",
+			ctx:            context.Background(),
+			authorization:  os.Getenv("COZE_TOKEN"),
+			conversationId: "7414413032111063080",
+			beforeId:       "1",
+			afterId:        "2",
+			wantErr:        requireValidationError,
 		},
 		{
 			name:           "success",
@@ -185,6 +214,107 @@ func TestListRequest_Do(t *testing.T) {
 	}
 }
 
+func TestListRequest_WithSectionId(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/conversation/message/list", func(w http.ResponseWriter, r *http.Request) {
+		cozetest.WriteJSON(w, map[string]any{
+			"code": 0,
+			"data": []response.Message{
+				{Id: "3", SectionId: "section-2"},
+				{Id: "2", SectionId: "section-1"},
+				{Id: "1", SectionId: "section-1"},
+			},
+			"first_id": "3",
+			"last_id":  "1",
+			"has_more": true,
+		})
+	})
+	cozetest.NewServer(t, mux)
+
+	resp, err := NewMessage("token", "conversation").ListRequest().WithSectionId("section-1").Do(context.Background())
+	require.NoError(t, err)
+	require.Len(t, resp.Data, 2)
+	require.Equal(t, "2", resp.Data[0].Id)
+	require.Equal(t, "3", resp.FirstId)
+	require.True(t, resp.HasMore)
+}
+
+// newFakeMessageList 模拟包含 total 条消息（ID 为 1 ~ total）的消息列表接口。
+func newFakeMessageList(t *testing.T, total int) *[]ListRequest {
+	var requests []ListRequest
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/conversation/message/list", func(w http.ResponseWriter, r *http.Request) {
+		var req ListRequest
+		require.NoError(t, jsoniter.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+
+		start, end := 1, total+1
+		if req.AfterId != "" {
+			start, _ = strconv.Atoi(req.AfterId)
+			start++
+			end = start + req.Limit
+		} else if req.BeforeId != "" {
+			end, _ = strconv.Atoi(req.BeforeId)
+			start = end - req.Limit
+		} else {
+			end = start + req.Limit
+		}
+		if start < 1 {
+			start = 1
+		}
+		if end > total+1 {
+			end = total + 1
+		}
+		messages := make([]response.Message, 0)
+		for i := start; i < end; i++ {
+			messages = append(messages, response.Message{Id: strconv.Itoa(i)})
+		}
+		resp := map[string]any{"code": 0, "data": messages, "has_more": false}
+		if len(messages) > 0 {
+			resp["first_id"] = messages[0].Id
+			resp["last_id"] = messages[len(messages)-1].Id
+			if req.BeforeId != "" {
+				resp["has_more"] = start > 1
+			} else {
+				resp["has_more"] = end <= total
+			}
+		}
+		cozetest.WriteJSON(w, resp)
+	})
+	cozetest.NewServer(t, mux)
+	return &requests
+}
+
+func TestListIterator(t *testing.T) {
+	requests := newFakeMessageList(t, 120)
+
+	messages, err := NewMessage("token", "conversation").ListRequest().All(context.Background())
+	require.NoError(t, err)
+	require.Len(t, messages, 120)
+	require.Equal(t, "120", messages[119].Id)
+	require.Len(t, *requests, 3)
+	require.Equal(t, MaxListLimit, (*requests)[0].Limit)
+	require.Equal(t, "100", (*requests)[2].AfterId)
+
+	*requests = nil
+	it := NewMessage("token", "conversation").ListRequest().WithBeforeId("101").WithLimit(30).Iterator(PageBackward)
+	var ids []string
+	for it.Next(context.Background()) {
+		ids = append(ids, it.Item().Id)
+	}
+	require.NoError(t, it.Err())
+	require.Len(t, ids, 100)
+	require.Equal(t, []string{"101", "71", "41", "11"}, []string{(*requests)[0].BeforeId, (*requests)[1].BeforeId, (*requests)[2].BeforeId, (*requests)[3].BeforeId})
+
+	// 提前结束遍历时不会查询后续的页面。
+	*requests = nil
+	it = NewMessage("token", "conversation").ListRequest().WithLimit(10).Iterator(PageForward)
+	for i := 0; i < 10; i++ {
+		require.True(t, it.Next(context.Background()))
+	}
+	require.Len(t, *requests, 1)
+}
+
 func TestRetrieveRequest_Do(t *testing.T) {
 	resp, err := NewMessage(os.Getenv("COZE_TOKEN"), "7414413032111063080").ListRequest().Do(context.Background())
 	require.NoError(t, err)
@@ -314,9 +444,7 @@ func TestModifyRequest_Do(t *testing.T) {
 			authorization:  os.Getenv("COZE_TOKEN"),
 			conversationId: "",
 			messageId:      messageId,
-			want: func(t *testing.T, resp *ModifyResponse[response.Message]) {
-				require.Equal(t, 4002, resp.Code)
-			},
+			wantErr:        requireValidationError,
 		},
 		{
 			name:           "empty messageId",
@@ -324,9 +452,7 @@ func TestModifyRequest_Do(t *testing.T) {
 			authorization:  os.Getenv("COZE_TOKEN"),
 			conversationId: "7414413032111063080",
 			messageId:      "",
-			want: func(t *testing.T, resp *ModifyResponse[response.Message]) {
-				require.Equal(t, 4005, resp.Code)
-			},
+			wantErr:        requireValidationError,
 		},
 		{
 			name:           "success",
@@ -451,3 +577,8 @@ func TestDeleteRequest_Do(t *testing.T) {
 		})
 	}
 }
+
+func requireValidationError(t require.TestingT, err error, i ...interface{}) {
+	var validationErr *request.ValidationError
+	require.True(t, errors.As(err, &validationErr), "unexpected error: %v", err)
+}
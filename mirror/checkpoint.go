@@ -0,0 +1,109 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"context"
+	"sync"
+
+	"github.com/chenmingyong0423/go-coze/internal/jsonfile"
+)
+
+// Cursor 记录一个会话的同步进度。
+type Cursor struct {
+	// 已同步的最后一条消息 ID，下次同步时从这条消息之后开始拉取。
+	AfterId string `json:"after_id,omitempty"`
+	// 最近若干条消息的更新时间，用于检测消息是否被修改。
+	UpdateTimes map[string]int64 `json:"update_times,omitempty"`
+}
+
+// Checkpoint 持久化每个会话的同步进度，实现必须是并发安全的。
+type Checkpoint interface {
+	// Load 返回会话的同步进度，从未同步过时返回 nil。
+	Load(ctx context.Context, conversationId string) (*Cursor, error)
+	// Save 保存会话的同步进度。
+	Save(ctx context.Context, conversationId string, cursor *Cursor) error
+}
+
+// MemoryCheckpoint 是基于内存的 Checkpoint，进程重启后数据会丢失。
+type MemoryCheckpoint struct {
+	mu      sync.Mutex
+	cursors map[string]Cursor
+}
+
+func NewMemoryCheckpoint() *MemoryCheckpoint {
+	return &MemoryCheckpoint{cursors: make(map[string]Cursor)}
+}
+
+func (m *MemoryCheckpoint) Load(_ context.Context, conversationId string) (*Cursor, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cursor, ok := m.cursors[conversationId]
+	if !ok {
+		return nil, nil
+	}
+	return &cursor, nil
+}
+
+func (m *MemoryCheckpoint) Save(_ context.Context, conversationId string, cursor *Cursor) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cursors[conversationId] = *cursor
+	return nil
+}
+
+// FileCheckpoint 是基于 JSON 文件的 Checkpoint，所有会话的同步进度保存在同一个文件中。
+// 写入时通过临时文件加重命名的方式保证文件完整，但不提供跨进程的锁。
+type FileCheckpoint struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileCheckpoint(path string) *FileCheckpoint {
+	return &FileCheckpoint{path: path}
+}
+
+func (f *FileCheckpoint) Load(_ context.Context, conversationId string) (*Cursor, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	cursors, err := f.load()
+	if err != nil {
+		return nil, err
+	}
+	cursor, ok := cursors[conversationId]
+	if !ok {
+		return nil, nil
+	}
+	return &cursor, nil
+}
+
+func (f *FileCheckpoint) Save(_ context.Context, conversationId string, cursor *Cursor) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	cursors, err := f.load()
+	if err != nil {
+		return err
+	}
+	cursors[conversationId] = *cursor
+	return jsonfile.Write(f.path, cursors)
+}
+
+func (f *FileCheckpoint) load() (map[string]Cursor, error) {
+	cursors := make(map[string]Cursor)
+	if err := jsonfile.Read(f.path, &cursors); err != nil {
+		return nil, err
+	}
+	return cursors, nil
+}
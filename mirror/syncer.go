@@ -0,0 +1,208 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mirror 将 Coze 会话中的消息增量同步到外部存储：
+// 每次同步从持久化的游标处拉取新消息，并通过更新时间检测最近的消息是否被修改。
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/chenmingyong0423/go-coze/internal/bulk"
+	"github.com/chenmingyong0423/go-coze/message"
+)
+
+// DefaultUpdateWindow 默认检测修改的最近消息数量。
+const DefaultUpdateWindow = 20
+
+// EventType 表示同步事件的类型。
+type EventType string
+
+const (
+	// EventCreated 表示会话中出现了新消息。
+	EventCreated EventType = "created"
+	// EventUpdated 表示已同步过的消息被修改。
+	EventUpdated EventType = "updated"
+)
+
+// Event 表示一次消息变更。
+type Event struct {
+	Type           EventType
+	ConversationId string
+	Message        response.Message
+}
+
+// Handler 处理同步事件，返回错误时本次同步中止且不保存游标，下次同步会重新投递这些事件。
+type Handler func(ctx context.Context, event Event) error
+
+// SyncError 表示部分会话同步失败，Failed 中保存同步失败的会话 ID 及对应的错误。
+type SyncError = bulk.Error
+
+// Syncer 增量同步被跟踪的会话中的消息，事件至少投递一次。
+// 同一个会话的同步是串行的，可以在多个 goroutine 中安全使用。
+type Syncer struct {
+	authorization string
+	checkpoint    Checkpoint
+	handler       Handler
+	updateWindow  int
+
+	mu            sync.Mutex
+	conversations []string
+	// 每个会话一把锁，避免同一个会话被并发同步而重复投递事件。
+	locks map[string]*sync.Mutex
+}
+
+func NewSyncer(authorization string, checkpoint Checkpoint, handler Handler) *Syncer {
+	return &Syncer{
+		authorization: authorization,
+		checkpoint:    checkpoint,
+		handler:       handler,
+		updateWindow:  DefaultUpdateWindow,
+		locks:         make(map[string]*sync.Mutex),
+	}
+}
+
+// WithUpdateWindow 设置检测修改的最近消息数量，最多为 message.MaxListLimit，为 0 时不检测修改。
+// 只有最近的 window 条消息的修改会被检测到。
+func (s *Syncer) WithUpdateWindow(window int) *Syncer {
+	if window > message.MaxListLimit {
+		window = message.MaxListLimit
+	}
+	s.updateWindow = window
+	return s
+}
+
+// Track 跟踪会话，已跟踪的会话会被忽略。
+func (s *Syncer) Track(conversationIds ...string) *Syncer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range conversationIds {
+		if _, ok := s.locks[id]; ok {
+			continue
+		}
+		s.locks[id] = new(sync.Mutex)
+		s.conversations = append(s.conversations, id)
+	}
+	return s
+}
+
+// Conversations 返回被跟踪的会话 ID。
+func (s *Syncer) Conversations() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.conversations...)
+}
+
+func (s *Syncer) lock(conversationId string) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.locks[conversationId]
+	if !ok {
+		l = new(sync.Mutex)
+		s.locks[conversationId] = l
+	}
+	return l
+}
+
+// SyncAll 依次同步所有被跟踪的会话，部分会话同步失败时返回 *SyncError，其余会话不受影响。
+func (s *Syncer) SyncAll(ctx context.Context) error {
+	failed := make(map[string]error)
+	for _, id := range s.Conversations() {
+		if err := s.Sync(ctx, id); err != nil {
+			failed[id] = err
+		}
+	}
+	if len(failed) > 0 {
+		return &SyncError{Op: "sync", Failed: failed}
+	}
+	return nil
+}
+
+// Run 每隔 interval 同步一次所有被跟踪的会话，直到 ctx 结束。同步失败时调用 onError（可以为 nil）后继续。
+func (s *Syncer) Run(ctx context.Context, interval time.Duration, onError func(error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.SyncAll(ctx); err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync 同步一个会话：先从游标处向后拉取新消息并投递 created 事件，
+// 再查询最近的消息，更新时间与游标中记录的不同时投递 updated 事件，所有事件处理成功后保存游标。
+func (s *Syncer) Sync(ctx context.Context, conversationId string) error {
+	l := s.lock(conversationId)
+	l.Lock()
+	defer l.Unlock()
+
+	cursor, err := s.checkpoint.Load(ctx, conversationId)
+	if err != nil {
+		return fmt.Errorf("mirror: load cursor: %w", err)
+	}
+	if cursor == nil {
+		cursor = &Cursor{}
+	}
+	next := &Cursor{AfterId: cursor.AfterId, UpdateTimes: make(map[string]int64)}
+
+	m := message.NewMessage(s.authorization, conversationId)
+	created := make(map[string]int64)
+	p := m.ListRequest().WithOrder(message.OrderAsc).WithAfterId(cursor.AfterId).Iterator(message.PageForward)
+	for p.Next(ctx) {
+		msg := p.Item()
+		if err = s.handler(ctx, Event{Type: EventCreated, ConversationId: conversationId, Message: msg}); err != nil {
+			return err
+		}
+		created[msg.Id] = msg.UpdateTime
+		next.AfterId = msg.Id
+	}
+	if err = p.Err(); err != nil {
+		return err
+	}
+
+	if s.updateWindow > 0 {
+		resp, err := m.ListRequest().WithOrder(message.OrderDesc).WithLimit(s.updateWindow).Do(ctx)
+		if err != nil {
+			return err
+		}
+		if err = resp.Err(); err != nil {
+			return err
+		}
+		for _, msg := range resp.Data {
+			next.UpdateTimes[msg.Id] = msg.UpdateTime
+			if _, ok := created[msg.Id]; ok {
+				continue
+			}
+			if prev, ok := cursor.UpdateTimes[msg.Id]; ok && prev != msg.UpdateTime {
+				if err = s.handler(ctx, Event{Type: EventUpdated, ConversationId: conversationId, Message: msg}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err = s.checkpoint.Save(ctx, conversationId, next); err != nil {
+		return fmt.Errorf("mirror: save cursor: %w", err)
+	}
+	return nil
+}
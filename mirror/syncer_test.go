@@ -0,0 +1,180 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/chenmingyong0423/go-coze/internal/cozetest"
+	"github.com/chenmingyong0423/go-coze/message"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMessages 模拟会话消息列表接口，消息按时间升序保存。
+type fakeMessages struct {
+	mu       sync.Mutex
+	messages map[string][]response.Message
+}
+
+func newFakeMessages(t *testing.T) *fakeMessages {
+	f := &fakeMessages{messages: make(map[string][]response.Message)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/conversation/message/list", func(w http.ResponseWriter, r *http.Request) {
+		var req message.ListRequest
+		require.NoError(t, jsoniter.NewDecoder(r.Body).Decode(&req))
+		f.mu.Lock()
+		all := append([]response.Message(nil), f.messages[r.URL.Query().Get("conversation_id")]...)
+		f.mu.Unlock()
+
+		if req.Order == message.OrderDesc {
+			for i, j := 0, len(all)-1; i < j; i, j = i+1, j-1 {
+				all[i], all[j] = all[j], all[i]
+			}
+		}
+		start := 0
+		for i, m := range all {
+			if m.Id == req.AfterId {
+				start = i + 1
+			}
+		}
+		end := start + req.Limit
+		if end > len(all) {
+			end = len(all)
+		}
+		page := all[start:end]
+		resp := map[string]any{"code": 0, "data": page, "has_more": end < len(all)}
+		if len(page) > 0 {
+			resp["last_id"] = page[len(page)-1].Id
+		}
+		cozetest.WriteJSON(w, resp)
+	})
+	cozetest.NewServer(t, mux)
+	return f
+}
+
+func (f *fakeMessages) add(conversationId string, n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("%s-%d", conversationId, len(f.messages[conversationId])+1)
+		f.messages[conversationId] = append(f.messages[conversationId], response.Message{Id: id, ConversationId: conversationId, UpdateTime: 1})
+	}
+}
+
+func (f *fakeMessages) modify(conversationId, messageId string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, m := range f.messages[conversationId] {
+		if m.Id == messageId {
+			f.messages[conversationId][i].UpdateTime++
+		}
+	}
+}
+
+type recorder struct {
+	events []string
+	// 处理到该消息时返回错误。
+	failOn string
+}
+
+func (r *recorder) handle(_ context.Context, event Event) error {
+	if event.Message.Id == r.failOn {
+		return errors.New("handler failed")
+	}
+	r.events = append(r.events, string(event.Type)+":"+event.Message.Id)
+	return nil
+}
+
+func TestSyncer_Sync(t *testing.T) {
+	f := newFakeMessages(t)
+	f.add("c1", 60)
+	rec := &recorder{}
+	checkpoint := NewMemoryCheckpoint()
+	s := NewSyncer("token", checkpoint, rec.handle).WithUpdateWindow(5)
+	ctx := context.Background()
+
+	require.NoError(t, s.Sync(ctx, "c1"))
+	require.Len(t, rec.events, 60)
+	require.Equal(t, "created:c1-1", rec.events[0])
+	require.Equal(t, "created:c1-60", rec.events[59])
+	cursor, err := checkpoint.Load(ctx, "c1")
+	require.NoError(t, err)
+	require.Equal(t, "c1-60", cursor.AfterId)
+	require.Len(t, cursor.UpdateTimes, 5)
+
+	// 没有变化时不投递事件
+	rec.events = nil
+	require.NoError(t, s.Sync(ctx, "c1"))
+	require.Empty(t, rec.events)
+
+	// 新消息和窗口内的修改都会被投递，窗口外的修改不会被检测到
+	f.add("c1", 2)
+	f.modify("c1", "c1-60")
+	f.modify("c1", "c1-1")
+	require.NoError(t, s.Sync(ctx, "c1"))
+	require.Equal(t, []string{"created:c1-61", "created:c1-62", "updated:c1-60"}, rec.events)
+
+	// 处理失败时不保存游标，下次同步重新投递
+	rec.events = nil
+	f.add("c1", 2)
+	rec.failOn = "c1-64"
+	require.Error(t, s.Sync(ctx, "c1"))
+	require.Equal(t, []string{"created:c1-63"}, rec.events)
+	rec.failOn = ""
+	require.NoError(t, s.Sync(ctx, "c1"))
+	require.Equal(t, []string{"created:c1-63", "created:c1-63", "created:c1-64"}, rec.events)
+}
+
+func TestSyncer_SyncAll(t *testing.T) {
+	f := newFakeMessages(t)
+	f.add("c1", 1)
+	f.add("c2", 2)
+	rec := &recorder{failOn: "c2-2"}
+	s := NewSyncer("token", NewMemoryCheckpoint(), rec.handle).Track("c1", "c2", "c1")
+	require.Equal(t, []string{"c1", "c2"}, s.Conversations())
+
+	err := s.SyncAll(context.Background())
+	var syncErr *SyncError
+	require.True(t, errors.As(err, &syncErr))
+	require.Len(t, syncErr.Failed, 1)
+	require.Contains(t, syncErr.Failed, "c2")
+	require.Equal(t, []string{"created:c1-1", "created:c2-1"}, rec.events)
+}
+
+func TestFileCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cursors.json")
+	checkpoint := NewFileCheckpoint(path)
+
+	cursor, err := checkpoint.Load(ctx, "c1")
+	require.NoError(t, err)
+	require.Nil(t, cursor)
+
+	want := &Cursor{AfterId: "m1", UpdateTimes: map[string]int64{"m1": 1}}
+	require.NoError(t, checkpoint.Save(ctx, "c1", want))
+	require.NoError(t, checkpoint.Save(ctx, "c2", &Cursor{AfterId: "m2"}))
+
+	cursor, err = NewFileCheckpoint(path).Load(ctx, "c1")
+	require.NoError(t, err)
+	require.Equal(t, want, cursor)
+}
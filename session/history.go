@@ -0,0 +1,192 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"sync"
+	"unicode"
+
+	chat "github.com/chenmingyong0423/go-coze/chat/v3"
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/chenmingyong0423/go-coze/common/response"
+)
+
+const (
+	// 每条消息除内容外的固定开销（角色、分隔符等）。
+	messageTokenOverhead = 4
+	// 每个文件、图片或音频部分的估算 token 数。
+	fileTokenEstimate = 256
+)
+
+// TokenEstimator 估算一条消息占用的 token 数。
+type TokenEstimator func(message request.EnterMessage) int
+
+// EstimateTokens 是默认的 token 估算方法：每个中日韩字符记为 1 个 token，其他字符每 4 个记为 1 个 token，
+// 多模态消息中的每个文件部分按固定值估算。该结果只是近似值，需要精确控制时请通过 WithEstimator 提供模型对应的分词器。
+func EstimateTokens(message request.EnterMessage) int {
+	tokens := messageTokenOverhead
+	if message.ContentType == request.ContentTypeObjectString {
+		if objectStrings, err := (response.Message{ContentType: message.ContentType, Content: message.Content}).ObjectStrings(); err == nil {
+			for _, objectString := range objectStrings {
+				if objectString.Type == request.ObjectStringTypeText {
+					tokens += estimateTextTokens(objectString.Text)
+				} else {
+					tokens += fileTokenEstimate
+				}
+			}
+			return tokens
+		}
+	}
+	return tokens + estimateTextTokens(message.Content)
+}
+
+func estimateTextTokens(text string) int {
+	var cjk, others int
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r) {
+			cjk++
+		} else {
+			others++
+		}
+	}
+	return cjk + (others+3)/4
+}
+
+// HistoryManager 在客户端维护对话历史，适用于 AutoSaveHistory 为 false、需要自行通过 AdditionalMessages 传递上下文的场景。
+// 构建请求时会始终保留固定消息，并在 token 预算内尽可能多地选择最近的消息。HistoryManager 是并发安全的。
+type HistoryManager struct {
+	mu        sync.Mutex
+	budget    int
+	estimator TokenEstimator
+	pinned    []request.EnterMessage
+	messages  []request.EnterMessage
+}
+
+// NewHistoryManager 创建一个 token 预算为 budget 的历史管理器，budget 小于等于 0 时不做截断。
+func NewHistoryManager(budget int) *HistoryManager {
+	return &HistoryManager{budget: budget, estimator: EstimateTokens}
+}
+
+func (h *HistoryManager) WithEstimator(estimator TokenEstimator) *HistoryManager {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.estimator = estimator
+	return h
+}
+
+// Pin 添加固定消息，例如背景设定或上下文说明，固定消息始终位于历史的最前面且不会被截断。
+func (h *HistoryManager) Pin(messages ...request.EnterMessage) *HistoryManager {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pinned = append(h.pinned, messages...)
+	return h
+}
+
+// Add 按时间顺序追加历史消息。
+func (h *HistoryManager) Add(messages ...request.EnterMessage) *HistoryManager {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages = append(h.messages, messages...)
+	return h
+}
+
+// AddResponse 追加 Bot 的回复，只有 type 为 answer 的消息会被记录；卡片不能作为输入消息，因此也不会被记录。
+func (h *HistoryManager) AddResponse(messages ...response.Message) *HistoryManager {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, message := range messages {
+		if message.Type != response.MessageTypeAnswer || message.ContentType == request.ContentTypeCard {
+			continue
+		}
+		h.messages = append(h.messages, request.EnterMessage{
+			Role:        message.Role,
+			Type:        message.Type,
+			Content:     message.Content,
+			ContentType: message.ContentType,
+		})
+	}
+	return h
+}
+
+// Messages 返回所有固定消息和历史消息，不做截断。
+func (h *HistoryManager) Messages() []request.EnterMessage {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	all := make([]request.EnterMessage, 0, len(h.pinned)+len(h.messages))
+	all = append(all, h.pinned...)
+	return append(all, h.messages...)
+}
+
+// Reset 清空历史消息，固定消息会被保留。
+func (h *HistoryManager) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages = nil
+}
+
+// Select 返回在 token 预算内的上下文：全部固定消息，加上最近的、完整的若干轮历史消息。
+// reserved 为需要额外预留的 token 数，例如本轮即将发送的消息。
+func (h *HistoryManager) Select(reserved int) []request.EnterMessage {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.selectLocked(reserved)
+}
+
+func (h *HistoryManager) selectLocked(reserved int) []request.EnterMessage {
+	selected := make([]request.EnterMessage, 0, len(h.pinned)+len(h.messages))
+	selected = append(selected, h.pinned...)
+	if h.budget <= 0 {
+		return append(selected, h.messages...)
+	}
+
+	remaining := h.budget - reserved
+	for _, message := range h.pinned {
+		remaining -= h.estimator(message)
+	}
+	// 以轮为单位截断：一轮从用户消息开始，包含其后 Bot 的回复，避免只保留回复而丢掉对应的提问。
+	start := len(h.messages)
+	for start > 0 {
+		turnStart := start - 1
+		for turnStart > 0 && h.messages[turnStart].Role != request.RoleUser {
+			turnStart--
+		}
+		var tokens int
+		for _, message := range h.messages[turnStart:start] {
+			tokens += h.estimator(message)
+		}
+		if tokens > remaining {
+			break
+		}
+		remaining -= tokens
+		start = turnStart
+	}
+	return append(selected, h.messages[start:]...)
+}
+
+// Build 基于模板构建本轮对话请求：关闭 AutoSaveHistory，并将预算内的历史消息和本轮消息一起作为 AdditionalMessages 传递。
+// 本轮消息会被追加到历史中，收到回复后请调用 AddResponse 记录 Bot 的回复。
+func (h *HistoryManager) Build(template *chat.CreateRequestTemplate, messages ...request.EnterMessage) *chat.CreateRequest {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var reserved int
+	for _, message := range messages {
+		reserved += h.estimator(message)
+	}
+	selected := h.selectLocked(reserved)
+	h.messages = append(h.messages, messages...)
+
+	return template.Request().WithAutoSaveHistory(false).AddMessages(selected...).AddMessages(messages...)
+}
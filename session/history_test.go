@@ -0,0 +1,116 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"testing"
+
+	chat "github.com/chenmingyong0423/go-coze/chat/v3"
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	testCases := []struct {
+		name    string
+		message request.EnterMessage
+		want    int
+	}{
+		{
+			name:    "empty",
+			message: request.EnterMessage{Role: "user"},
+			want:    messageTokenOverhead,
+		},
+		{
+			name:    "chinese",
+			message: userMessage("你好世界"),
+			want:    messageTokenOverhead + 4,
+		},
+		{
+			name:    "english",
+			message: userMessage("hello world"),
+			want:    messageTokenOverhead + 3,
+		},
+		{
+			name: "object_string",
+			message: request.NewEnterMessageBuilder().Role("user").
+				ObjectStringContent(request.NewObjectStringsBuilder().Text("这是什么").Image("123", "").Build()...).Build(),
+			want: messageTokenOverhead + 4 + fileTokenEstimate,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, EstimateTokens(tc.message))
+		})
+	}
+}
+
+func TestHistoryManager_Select(t *testing.T) {
+	// 每条消息固定占用 10 个 token。
+	estimator := func(message request.EnterMessage) int { return 10 }
+
+	h := NewHistoryManager(45).WithEstimator(estimator).
+		Pin(userMessage("背景设定")).
+		Add(userMessage("1"), userMessage("2"), userMessage("3"), userMessage("4"))
+
+	require.Equal(t, []request.EnterMessage{userMessage("背景设定"), userMessage("2"), userMessage("3"), userMessage("4")}, h.Select(0))
+	require.Equal(t, []request.EnterMessage{userMessage("背景设定"), userMessage("3"), userMessage("4")}, h.Select(10))
+	require.Equal(t, []request.EnterMessage{userMessage("背景设定")}, h.Select(40))
+	require.Len(t, h.Messages(), 5)
+
+	h.Reset()
+	require.Equal(t, []request.EnterMessage{userMessage("背景设定")}, h.Select(0))
+
+	unlimited := NewHistoryManager(0).Add(userMessage("1"), userMessage("2"))
+	require.Len(t, unlimited.Select(1000), 2)
+}
+
+func TestHistoryManager_SelectWholeTurns(t *testing.T) {
+	estimator := func(message request.EnterMessage) int { return 10 }
+	answer := func(content string) request.EnterMessage {
+		return request.EnterMessage{Role: "assistant", Type: "answer", Content: content, ContentType: "text"}
+	}
+
+	h := NewHistoryManager(50).WithEstimator(estimator).
+		Add(userMessage("1"), answer("一"), userMessage("2"), answer("二"), answer("二续"))
+
+	// 预算只够最后一轮中的两条消息时，整轮都会被丢弃，而不是只保留回复。
+	require.Empty(t, h.Select(30))
+	require.Equal(t, []request.EnterMessage{userMessage("2"), answer("二"), answer("二续")}, h.Select(20))
+	require.Equal(t, []request.EnterMessage{userMessage("2"), answer("二"), answer("二续")}, h.Select(10))
+	require.Len(t, h.Select(0), 5)
+}
+
+func TestHistoryManager_Build(t *testing.T) {
+	estimator := func(message request.EnterMessage) int { return 10 }
+	h := NewHistoryManager(40).WithEstimator(estimator).Pin(userMessage("背景设定"))
+	template := chat.NewChat("token", "user", "bot").Template().WithAutoSaveHistory(true)
+
+	r := h.Build(template, userMessage("1"))
+	require.False(t, r.AutoSaveHistory)
+	require.Equal(t, []request.EnterMessage{userMessage("背景设定"), userMessage("1")}, r.AdditionalMessages)
+
+	h.AddResponse(
+		response.Message{Role: "assistant", Type: "answer", Content: "一", ContentType: "text"},
+		response.Message{Role: "assistant", Type: "follow_up", Content: "还有吗", ContentType: "text"},
+		response.Message{Role: "assistant", Type: "answer", Content: `{"card_type":3}`, ContentType: "card"},
+	)
+	answer := request.EnterMessage{Role: "assistant", Type: "answer", Content: "一", ContentType: "text"}
+
+	r = h.Build(template, userMessage("2"))
+	require.Equal(t, []request.EnterMessage{userMessage("背景设定"), userMessage("1"), answer, userMessage("2")}, r.AdditionalMessages)
+	require.NoError(t, r.Validate())
+}
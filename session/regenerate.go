@@ -0,0 +1,196 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/chenmingyong0423/go-coze/message"
+)
+
+// ErrNoUserMessage 表示在会话最近的消息中找不到可以编辑的用户消息。
+var ErrNoUserMessage = errors.New("session: no user message found in the latest messages")
+
+// lastTurn 查询会话中最后一条用户消息以及其后 Bot 回复的消息，回复按时间倒序排列。
+// 只在最近的 message.MaxListLimit 条消息中查找。
+func lastTurn(ctx context.Context, m *message.Message) (*response.Message, []response.Message, error) {
+	resp, err := m.ListRequest().WithOrder(message.OrderDesc).WithLimit(message.MaxListLimit).Do(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = resp.Err(); err != nil {
+		return nil, nil, err
+	}
+	for i, msg := range resp.Data {
+		if msg.Role == request.RoleUser {
+			return &resp.Data[i], resp.Data[:i], nil
+		}
+	}
+	return nil, nil, ErrNoUserMessage
+}
+
+// EditLastTurn 将会话中最后一条用户消息修改为 edited 的内容，删除其后 Bot 回复的所有消息，然后发起新的对话让 Bot 重新回答。
+// edited 中只使用 Content、ContentType 和 MetaData；MetaData 为空时保留原消息的附加信息。
+//
+// 修改之后的任何一步失败（包括删除消息、发起对话、等待对话结束和 ctx 被取消）都会尽量回滚：删除新对话已生成的消息，恢复用户消息的原内容，
+// 并重新创建已删除的 answer 消息；本地的对话 ID 列表、历史消息和切换会话的统计也会恢复到修改之前。
+// 重新创建的消息 ID 会发生变化，工具调用、推荐问题等中间消息无法重新创建。回滚本身失败时，错误信息中会包含回滚的错误。
+func (s *Session) EditLastTurn(ctx context.Context, edited request.EnterMessage) (*Turn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadConversation(ctx); err != nil {
+		return nil, err
+	}
+	conversationId := s.conversationId
+	if conversationId == "" {
+		return nil, ErrNoUserMessage
+	}
+
+	m := message.NewMessage(s.authorization, conversationId)
+	question, answers, err := lastTurn(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+
+	modify := m.ModifyRequest(question.Id)
+	modify.Content, modify.ContentType, modify.Meta = edited.Content, edited.ContentType, edited.MetaData
+	if modify.Meta == nil {
+		modify.Meta = question.MetaData
+	}
+	if err = doModify(ctx, modify); err != nil {
+		return nil, err
+	}
+
+	var (
+		deleted   []response.Message
+		generated *response.Chat
+	)
+	// 本地状态在发起对话后会被修改，回滚时恢复。replaceLastInput 会原地修改 history，因此需要复制。
+	chatIds, history := len(s.chatIds), append([]response.Message(nil), s.history...)
+	sectionId, messageCount, tokenUsage := s.sectionId, s.messageCount, s.tokenUsage
+	// 回滚不使用调用方的 ctx，ctx 被取消后仍然能够完成回滚。
+	rollback := func(cause error) error {
+		s.chatIds, s.history = s.chatIds[:chatIds], history
+		s.sectionId, s.messageCount, s.tokenUsage = sectionId, messageCount, tokenUsage
+
+		rollbackCtx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+		defer cancel()
+		if rollbackErr := s.rollbackEdit(rollbackCtx, m, question, deleted, generated); rollbackErr != nil {
+			return fmt.Errorf("session: edit last turn: %w (rollback failed: %v)", cause, rollbackErr)
+		}
+		return cause
+	}
+
+	for _, answer := range answers {
+		resp, err := m.DeleteRequest(answer.Id).Do(ctx)
+		if err == nil {
+			err = resp.Err()
+		}
+		if err != nil {
+			return nil, rollback(err)
+		}
+		deleted = append(deleted, answer)
+	}
+
+	resp, err := s.template.Request().WithConversationId(conversationId).Do(ctx)
+	if err == nil {
+		err = resp.Err()
+	}
+	if err != nil {
+		return nil, rollback(err)
+	}
+	generated = resp.Data
+	s.chatIds = append(s.chatIds, resp.Data.Id)
+	s.replaceLastInput(resp.Data, edited)
+
+	turn, err := s.finish(ctx, conversationId, resp.Data, 0)
+	if err != nil {
+		return turn, rollback(err)
+	}
+	return turn, nil
+}
+
+func doModify(ctx context.Context, modify *message.ModifyRequest) error {
+	resp, err := modify.Do(ctx)
+	if err != nil {
+		return err
+	}
+	return resp.Err()
+}
+
+// rollbackEdit 删除 generated 对话已生成的消息，恢复用户消息的原内容，并按时间顺序重新创建已删除的 answer 消息。
+// generated 为 nil 表示还没有发起新的对话。
+func (s *Session) rollbackEdit(ctx context.Context, m *message.Message, question *response.Message, deleted []response.Message, generated *response.Chat) error {
+	if generated != nil {
+		// 发起对话时没有新增用户消息，新对话的消息都在最后一条用户消息之后。
+		_, answers, err := lastTurn(ctx, m)
+		if err != nil {
+			return err
+		}
+		for _, answer := range answers {
+			if answer.ChatId != generated.Id {
+				continue
+			}
+			resp, err := m.DeleteRequest(answer.Id).Do(ctx)
+			if err == nil {
+				err = resp.Err()
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	restore := m.ModifyRequest(question.Id)
+	restore.Content, restore.ContentType, restore.Meta = question.Content, question.ContentType, question.MetaData
+	if err := doModify(ctx, restore); err != nil {
+		return err
+	}
+	for i := len(deleted) - 1; i >= 0; i-- {
+		answer := deleted[i]
+		if answer.Type != response.MessageTypeAnswer {
+			continue
+		}
+		if answer.ContentType != request.ContentTypeText && answer.ContentType != request.ContentTypeObjectString {
+			continue
+		}
+		create := m.CreateRequest().WithRole(answer.Role)
+		create.Content, create.ContentType, create.Meta = answer.Content, answer.ContentType, answer.MetaData
+		resp, err := create.Do(ctx)
+		if err == nil {
+			err = resp.Err()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceLastInput 丢弃本地历史中最后一条用户消息及其后的消息，并记录修改后的用户消息，调用方需持有锁。
+func (s *Session) replaceLastInput(c *response.Chat, edited request.EnterMessage) {
+	for i := len(s.history) - 1; i >= 0; i-- {
+		if s.history[i].Role == request.RoleUser {
+			s.history = s.history[:i]
+			break
+		}
+	}
+	edited.Role = request.RoleUser
+	s.appendInputs(c, []request.EnterMessage{edited})
+}
@@ -0,0 +1,132 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/stretchr/testify/require"
+)
+
+func seedTurns() []response.Message {
+	return []response.Message{
+		{Id: "q1", Role: "user", Type: "question", Content: "第一个问题", ContentType: "text"},
+		{Id: "a1", Role: "assistant", Type: "answer", Content: "第一个回答", ContentType: "text"},
+		{Id: "q2", Role: "user", Type: "question", Content: "第二个问题", ContentType: "text"},
+		{Id: "a2", Role: "assistant", Type: "answer", Content: "第二个回答", ContentType: "text"},
+		{Id: "f2", Role: "assistant", Type: "follow_up", Content: "推荐问题", ContentType: "text"},
+	}
+}
+
+func TestSession_EditLastTurn(t *testing.T) {
+	t.Run("no conversation", func(t *testing.T) {
+		newFakeCoze(t)
+		_, err := NewSession("token", "user", "bot").EditLastTurn(context.Background(), userMessage("改"))
+		require.Equal(t, ErrNoUserMessage, err)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		f := newFakeCoze(t)
+		f.messages = seedTurns()
+		s := NewSession("token", "user", "bot").WithPollInterval(time.Millisecond).WithConversationId("conversation-1")
+
+		turn, err := s.EditLastTurn(context.Background(), userMessage("修改后的问题"))
+		require.NoError(t, err)
+		require.Equal(t, "你好", turn.Answer())
+		require.Equal(t, []response.Message{
+			seedTurns()[0],
+			seedTurns()[1],
+			{Id: "q2", Role: "user", Type: "question", Content: "修改后的问题", ContentType: "text"},
+		}, f.messages)
+		require.Equal(t, []string{"chat-1"}, s.ChatIds())
+		require.Equal(t, "修改后的问题", s.History()[0].Content)
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		f := newFakeCoze(t)
+		f.messages = seedTurns()
+		f.failDelete = true
+		s := NewSession("token", "user", "bot").WithPollInterval(time.Millisecond).WithConversationId("conversation-1")
+
+		_, err := s.EditLastTurn(context.Background(), userMessage("修改后的问题"))
+		var apiErr *response.ApiError
+		require.True(t, errors.As(err, &apiErr))
+		require.Equal(t, 5000, apiErr.Code)
+		require.Equal(t, seedTurns(), f.messages)
+		require.Empty(t, s.ChatIds())
+		require.Empty(t, s.History())
+	})
+
+	t.Run("rollback recreates answers", func(t *testing.T) {
+		f := newFakeCoze(t)
+		f.messages = seedTurns()
+		f.failChat = true
+		s := NewSession("token", "user", "bot").WithPollInterval(time.Millisecond).WithConversationId("conversation-1")
+
+		_, err := s.EditLastTurn(context.Background(), userMessage("修改后的问题"))
+		require.Error(t, err)
+		// follow_up 消息无法重新创建，answer 消息以新的 ID 重新创建
+		require.Len(t, f.messages, 4)
+		require.Equal(t, seedTurns()[:3], f.messages[:3])
+		require.Equal(t, "recreated-3", f.messages[3].Id)
+		require.Equal(t, "第二个回答", f.messages[3].Content)
+		require.Equal(t, "assistant", f.messages[3].Role)
+	})
+
+	t.Run("rollback deletes generated answers", func(t *testing.T) {
+		f := newFakeCoze(t)
+		f.messages = seedTurns()
+		s := NewSession("token", "user", "bot").WithPollInterval(time.Millisecond).WithConversationId("conversation-1")
+		_, err := s.Send(context.Background(), userMessage("你好"))
+		require.NoError(t, err)
+		history, messageCount, tokenUsage := s.History(), s.messageCount, s.tokenUsage
+
+		// 新对话已经生成了回答但以失败结束，回滚需要删除该回答，并恢复本地状态。
+		f.saveAnswers, f.failAnswers = true, true
+		turn, err := s.EditLastTurn(context.Background(), userMessage("修改后的问题"))
+		var apiErr *response.ApiError
+		require.True(t, errors.As(err, &apiErr), err)
+		require.Equal(t, "chat-2", turn.Chat.Id)
+		require.Len(t, f.messages, 4)
+		require.Equal(t, seedTurns()[:3], f.messages[:3])
+		require.Equal(t, "第二个回答", f.messages[3].Content)
+		require.Equal(t, []string{"chat-1"}, s.ChatIds())
+		require.Equal(t, history, s.History())
+		require.Equal(t, messageCount, s.messageCount)
+		require.Equal(t, tokenUsage, s.tokenUsage)
+	})
+
+	t.Run("rollback after cancel", func(t *testing.T) {
+		f := newFakeCoze(t)
+		f.messages = seedTurns()
+		s := NewSession("token", "user", "bot").WithPollInterval(time.Hour).WithConversationId("conversation-1")
+
+		// 对话已发起但在结束前 ctx 被取消，回滚仍然会执行。
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		turn, err := s.EditLastTurn(ctx, userMessage("修改后的问题"))
+		require.Nil(t, turn)
+		require.Equal(t, context.DeadlineExceeded, err)
+		require.Len(t, f.messages, 4)
+		require.Equal(t, seedTurns()[:3], f.messages[:3])
+		require.Equal(t, "第二个回答", f.messages[3].Content)
+		require.Empty(t, s.ChatIds())
+		require.Empty(t, s.History())
+	})
+}
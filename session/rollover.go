@@ -0,0 +1,162 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/chenmingyong0423/go-coze/message"
+)
+
+const defaultSummaryPrompt = "请总结以上对话的要点，包括用户的诉求、已经确认的信息和尚未解决的问题，用于在新的会话中继续对话。"
+
+// RolloverPolicy 定义会话过长时的摘要与切换策略：当前会话的消息数或 token 用量超过阈值后，
+// 在下一轮对话开始前由摘要 Bot 总结当前会话，并以摘要为初始消息创建新的会话，之后的对话在新会话中继续。
+type RolloverPolicy struct {
+	// 当前会话的消息数达到该值时触发切换，0 表示不限制。
+	MaxMessages int
+	// 当前会话累计的 token 用量达到该值时触发切换，0 表示不限制。
+	MaxTokens int
+	// 用于生成摘要的 Bot ID。
+	SummariserBotId string
+	// 要求摘要 Bot 进行总结的提示语，为空时使用默认提示语。
+	Prompt string
+	// 新会话的附加信息，其中的 conversation.MetaDataKeyUserId 总是会被设置为 Session 的用户 ID。
+	MetaData map[string]any
+	// 切换完成后的回调，可用于记录日志或通知业务方。
+	OnRollover func(oldConversationId, newConversationId, summary string)
+}
+
+func (p *RolloverPolicy) exceeded(messages, tokens int) bool {
+	return (p.MaxMessages > 0 && messages >= p.MaxMessages) || (p.MaxTokens > 0 && tokens >= p.MaxTokens)
+}
+
+// WithRollover 开启会话的自动摘要与切换。
+func (s *Session) WithRollover(policy RolloverPolicy) *Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rollover = &policy
+	return s
+}
+
+// rolloverIfNeeded 在超过阈值时切换到以摘要为初始消息的新会话，调用方需持有锁。
+func (s *Session) rolloverIfNeeded(ctx context.Context, conversationId string) (string, error) {
+	if s.rollover == nil || !s.rollover.exceeded(s.messageCount, s.tokenUsage) {
+		return conversationId, nil
+	}
+
+	summary, err := s.summarise(ctx, conversationId)
+	if err != nil {
+		return "", fmt.Errorf("session: summarise conversation %s: %w", conversationId, err)
+	}
+
+	summaryMessage := request.NewEnterMessageBuilder().Role(request.RoleAssistant).Type(response.MessageTypeAnswer).
+		Content(summary).ContentType(request.ContentTypeText).Build()
+	resp, err := s.conversation.CreateRequest().WithMessages(summaryMessage).WithMetaData(s.conversationMetaData(s.rollover.MetaData)).Do(ctx)
+	if err != nil {
+		return "", err
+	}
+	if err = resp.Err(); err != nil {
+		return "", err
+	}
+	if resp.Data.Id == "" {
+		return "", errors.New("session: empty conversation id")
+	}
+
+	s.conversationId = resp.Data.Id
+	s.trackConversation(s.conversationId)
+	if err = s.touch(ctx); err != nil {
+		return "", err
+	}
+	if s.rollover.OnRollover != nil {
+		s.rollover.OnRollover(conversationId, s.conversationId, summary)
+	}
+	return s.conversationId, nil
+}
+
+// summarise 将当前会话中的全部消息发送给摘要 Bot，返回摘要内容。
+// 消息从服务端分页读取，因此恢复的会话或重启后的 Session 也能总结完整的会话。
+func (s *Session) summarise(ctx context.Context, conversationId string) (string, error) {
+	history, err := message.NewMessage(s.authorization, conversationId).ListRequest().WithOrder(message.OrderAsc).All(ctx)
+	if err != nil {
+		return "", err
+	}
+	var messages []request.EnterMessage
+	for _, m := range history {
+		// 卡片不能作为输入消息。
+		if m.Content == "" || m.ContentType == request.ContentTypeCard {
+			continue
+		}
+		if m.Role == request.RoleAssistant && m.Type != response.MessageTypeAnswer {
+			continue
+		}
+		messages = append(messages, request.EnterMessage{
+			Role:        m.Role,
+			Type:        m.Type,
+			Content:     m.Content,
+			ContentType: m.ContentType,
+		})
+	}
+	prompt := s.rollover.Prompt
+	if prompt == "" {
+		prompt = defaultSummaryPrompt
+	}
+	messages = append(messages, request.NewEnterMessageBuilder().Role(request.RoleUser).Content(prompt).ContentType(request.ContentTypeText).Build())
+
+	// 摘要在独立的会话中进行，不会影响当前会话，用完后删除该会话。
+	summariser := NewSession(s.authorization, s.userId, s.rollover.SummariserBotId).WithPollInterval(s.pollInterval)
+	turn, err := summariser.Send(ctx, messages...)
+	if summariserId := summariser.ConversationId(); summariserId != "" {
+		s.deleteConversation(summariserId)
+	}
+	if err != nil {
+		return "", err
+	}
+	summary := turn.Answer()
+	if summary == "" {
+		return "", errors.New("empty summary")
+	}
+	return summary, nil
+}
+
+// deleteConversation 尽力删除会话，即使 ctx 已被取消也会执行。
+func (s *Session) deleteConversation(conversationId string) {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	_, _ = s.conversation.DeleteRequest().Do(ctx, conversationId)
+}
+
+// trackConversation 在会话切换时重置 section 以及消息数和 token 用量的统计，调用方需持有锁。
+func (s *Session) trackConversation(conversationId string) {
+	if s.trackedConversationId == conversationId {
+		return
+	}
+	s.trackedConversationId = conversationId
+	s.sectionId = ""
+	s.messageCount = 0
+	s.tokenUsage = 0
+}
+
+// trackChat 统计当前会话的消息数和 token 用量，调用方需持有锁。
+func (s *Session) trackChat(c *response.Chat, messages int) {
+	s.messageCount += messages
+	if c != nil {
+		s.tokenUsage += c.Usage.TokenCount
+	}
+}
@@ -0,0 +1,410 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package session 封装了与 Bot 的多轮对话：首次发送消息时自动创建会话，之后的每一轮对话都发生在同一个会话中。
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	chat "github.com/chenmingyong0423/go-coze/chat/v3"
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/chenmingyong0423/go-coze/conversation"
+)
+
+const (
+	defaultPollInterval = time.Second
+	// 清理临时会话或回滚修改时使用的超时时间，这些操作不受调用方 ctx 取消的影响。
+	cleanupTimeout = 5 * time.Second
+)
+
+// Session 表示某个用户与某个 Bot 之间的多轮对话。
+// Coze 不允许在同一个会话中同时进行多个对话，因此 Session 会串行执行每一轮对话，可以在多个 goroutine 中安全使用。
+type Session struct {
+	// 串行执行每一轮对话，保护下面的所有字段。
+	mu sync.Mutex
+
+	authorization string
+	userId        string
+	chat          *chat.Chat
+	conversation  *conversation.Conversation
+	template      *chat.CreateRequestTemplate
+	pollInterval  time.Duration
+
+	store    ConversationStore
+	storeKey string
+	storeTTL time.Duration
+
+	rollover *RolloverPolicy
+	// 用于判断是否需要切换会话的统计，仅针对 trackedConversationId 对应的会话。
+	trackedConversationId string
+	messageCount          int
+	tokenUsage            int
+
+	conversationId string
+	sectionId      string
+	chatIds        []string
+	history        []response.Message
+}
+
+func NewSession(authorization, userId, botId string) *Session {
+	c := chat.NewChat(authorization, userId, botId)
+	return &Session{
+		authorization: authorization,
+		userId:        userId,
+		chat:          c,
+		conversation:  conversation.NewConversation(authorization),
+		template:      c.Template().WithAutoSaveHistory(true),
+		pollInterval:  defaultPollInterval,
+		storeKey:      StoreKey(botId, userId),
+	}
+}
+
+// WithConversationId 指定已有的会话，用于恢复之前的多轮对话。设置了 store 时以 store 中保存的会话为准。
+func (s *Session) WithConversationId(conversationId string) *Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conversationId = conversationId
+	return s
+}
+
+// WithStore 设置会话存储，会话 ID 将以 Bot 和用户为键保存到 store 中，每轮对话成功后刷新过期时间。
+// 设置后每轮对话开始前都会从 store 中读取会话 ID：读取到则继续该会话，不存在或已过期则创建新的会话。
+func (s *Session) WithStore(store ConversationStore, ttl time.Duration) *Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store = store
+	s.storeTTL = ttl
+	return s
+}
+
+// WithTemplate 修改每一轮对话请求使用的模板，例如设置 Bot 变量或附加信息。
+func (s *Session) WithTemplate(fn func(template *chat.CreateRequestTemplate) *chat.CreateRequestTemplate) *Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.template = fn(s.template)
+	return s
+}
+
+// WithPollInterval 设置非流式对话轮询对话状态的时间间隔，默认为 1 秒。
+func (s *Session) WithPollInterval(pollInterval time.Duration) *Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pollInterval = pollInterval
+	return s
+}
+
+// ConversationId 返回当前会话 ID，会话尚未创建时返回空字符串。
+func (s *Session) ConversationId() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conversationId
+}
+
+// SectionId 返回当前会话最新的 section ID，来自 NewTopic 或最近一轮对话的响应，两者都没有时返回空字符串。
+// Coze 发起对话时不能指定 section，每轮对话总是在会话最新的 section 中进行，因此只能通过 NewTopic 切换 section。
+func (s *Session) SectionId() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sectionId
+}
+
+// NewTopic 清除会话上下文并开启新的 section，之后的对话不再参考之前的上下文，但会话中的历史消息仍然保留。
+// 会话尚未创建时会先创建会话。
+func (s *Session) NewTopic(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conversationId, err := s.ensureConversation(ctx)
+	if err != nil {
+		return "", err
+	}
+	resp, err := s.conversation.ClearRequest().Do(ctx, conversationId)
+	if err != nil {
+		return "", err
+	}
+	if err = resp.Err(); err != nil {
+		return "", err
+	}
+	s.sectionId = resp.Data.Id
+	return s.sectionId, nil
+}
+
+// ChatIds 返回本会话中已发起的所有对话 ID，按时间升序排列。
+func (s *Session) ChatIds() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.chatIds...)
+}
+
+// History 返回本会话中已发送和收到的消息，按时间升序排列。
+func (s *Session) History() []response.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]response.Message(nil), s.history...)
+}
+
+// Turn 表示一轮对话的结果。
+type Turn struct {
+	Chat *response.Chat
+	// Bot 在本轮对话中返回的消息。
+	Messages []response.Message
+}
+
+// Answer 返回本轮对话中 type 为 answer 的消息内容，多条消息之间不做分隔直接拼接。
+func (t *Turn) Answer() string {
+	var answer string
+	for _, message := range t.Messages {
+		if message.Type == response.MessageTypeAnswer {
+			answer += message.Content
+		}
+	}
+	return answer
+}
+
+// Send 发送一轮对话并等待对话结束，返回 Bot 在本轮对话中回复的消息。
+// 对话失败时会同时返回 Turn 和错误。
+func (s *Session) Send(ctx context.Context, messages ...request.EnterMessage) (*Turn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conversationId, err := s.ensureConversation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if conversationId, err = s.rolloverIfNeeded(ctx, conversationId); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.template.Request().WithConversationId(conversationId).AddMessages(messages...).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = resp.Err(); err != nil {
+		return nil, err
+	}
+	s.chatIds = append(s.chatIds, resp.Data.Id)
+	s.appendInputs(resp.Data, messages)
+	return s.finish(ctx, conversationId, resp.Data, len(messages))
+}
+
+// finish 等待已创建的对话结束并记录 Bot 回复的消息，调用方需持有锁。
+func (s *Session) finish(ctx context.Context, conversationId string, created *response.Chat, inputs int) (*Turn, error) {
+	c, err := s.chat.Wait(ctx, created, s.pollInterval)
+	if err != nil {
+		return nil, err
+	}
+	turn := &Turn{Chat: c}
+	s.trackChat(c, inputs)
+	s.trackSection(c)
+	if c.Status == response.ChatStatusFailed {
+		return turn, fmt.Errorf("session: chat %s failed: %w", c.Id, &response.ApiError{Code: c.LastError.Code, Msg: c.LastError.Msg})
+	}
+
+	listResp, err := s.chat.MessageListRequest(conversationId).Do(ctx, c.Id)
+	if err != nil {
+		return turn, err
+	}
+	if err = listResp.Err(); err != nil {
+		return turn, err
+	}
+	turn.Messages = listResp.Data
+	s.history = append(s.history, listResp.Data...)
+	s.trackChat(nil, len(listResp.Data))
+	if err = s.touch(ctx); err != nil {
+		return turn, err
+	}
+	return turn, nil
+}
+
+// SendStream 以流式的方式发送一轮对话，事件会原样转发给调用方。
+// 调用方必须读取两个 channel 直到它们都被关闭，或者在取消 ctx 后停止读取；下一轮对话会在本轮的流结束后才开始。
+func (s *Session) SendStream(ctx context.Context, messages ...request.EnterMessage) (<-chan *chat.StreamingResponse, <-chan error) {
+	respChan := make(chan *chat.StreamingResponse)
+	errChan := make(chan error)
+
+	s.mu.Lock()
+	go func() {
+		defer s.mu.Unlock()
+		defer close(respChan)
+		defer close(errChan)
+
+		// ctx 被取消后不再等待调用方读取，避免一直持有锁。
+		sendErr := func(err error) {
+			select {
+			case errChan <- err:
+			case <-ctx.Done():
+			}
+		}
+
+		conversationId, err := s.ensureConversation(ctx)
+		if err != nil {
+			sendErr(err)
+			return
+		}
+		if conversationId, err = s.rolloverIfNeeded(ctx, conversationId); err != nil {
+			sendErr(err)
+			return
+		}
+
+		// 记录流中最新的对话状态，流在对话结束前中断时先取消该对话再释放锁，避免下一轮对话与其重叠。
+		var current *response.Chat
+		defer func() { _ = s.chat.Cancel(current) }()
+
+		// 即使调用方已停止读取，也要读完上游的 channel，让上游的 goroutine 能够退出。
+		upstreamResp, upstreamErr := s.template.Request().WithConversationId(conversationId).AddMessages(messages...).DoStream(ctx)
+		for upstreamResp != nil || upstreamErr != nil {
+			select {
+			case resp, ok := <-upstreamResp:
+				if !ok {
+					upstreamResp = nil
+					continue
+				}
+				if resp.Chat != nil {
+					current = resp.Chat
+				}
+				s.record(resp, messages)
+				select {
+				case respChan <- resp:
+				case <-ctx.Done():
+					continue
+				}
+				if resp.Event == chat.EventChatCompleted {
+					if err = s.touch(ctx); err != nil {
+						sendErr(err)
+					}
+				}
+			case err, ok := <-upstreamErr:
+				if !ok {
+					upstreamErr = nil
+					continue
+				}
+				sendErr(err)
+			}
+		}
+	}()
+	return respChan, errChan
+}
+
+// record 根据流式事件记录对话 ID 和完整的消息，调用方需持有锁。
+func (s *Session) record(resp *chat.StreamingResponse, inputs []request.EnterMessage) {
+	switch {
+	case resp.Event == chat.EventChatCreated && resp.Chat != nil:
+		s.chatIds = append(s.chatIds, resp.Chat.Id)
+		s.appendInputs(resp.Chat, inputs)
+		s.trackChat(nil, len(inputs))
+		s.trackSection(resp.Chat)
+	case resp.Event == chat.EventChatCompleted && resp.Chat != nil:
+		s.trackChat(resp.Chat, 0)
+	case resp.Event == chat.EventMessageCompleted && resp.Message != nil:
+		s.history = append(s.history, *resp.Message)
+		s.trackChat(nil, 1)
+	}
+}
+
+// trackSection 记录对话所在的 section，调用方需持有锁。
+func (s *Session) trackSection(c *response.Chat) {
+	if c.SectionId != "" && c.ConversationId == s.conversationId {
+		s.sectionId = c.SectionId
+	}
+}
+
+// appendInputs 将本轮对话发送的消息记录到历史中，调用方需持有锁。
+func (s *Session) appendInputs(c *response.Chat, inputs []request.EnterMessage) {
+	now := time.Now().Unix()
+	for _, input := range inputs {
+		s.history = append(s.history, response.Message{
+			ConversationId: c.ConversationId,
+			BotId:          c.BotId,
+			ChatId:         c.Id,
+			MetaData:       input.MetaData,
+			Role:           input.Role,
+			Content:        input.Content,
+			ContentType:    input.ContentType,
+			CreateTime:     now,
+			UpdateTime:     now,
+			Type:           input.Type,
+		})
+	}
+}
+
+// loadConversation 设置了 store 时从 store 中读取当前会话 ID，不存在或已过期时清空会话 ID，调用方需持有锁。
+func (s *Session) loadConversation(ctx context.Context) error {
+	if s.store == nil {
+		return nil
+	}
+	conversationId, ok, err := s.store.Get(ctx, s.storeKey)
+	if err != nil {
+		return fmt.Errorf("session: get conversation from store: %w", err)
+	}
+	if !ok {
+		conversationId = ""
+	}
+	s.conversationId = conversationId
+	return nil
+}
+
+// ensureConversation 在会话不存在时创建会话，调用方需持有锁。
+func (s *Session) ensureConversation(ctx context.Context) (string, error) {
+	if err := s.loadConversation(ctx); err != nil {
+		return "", err
+	}
+	if s.conversationId != "" {
+		s.trackConversation(s.conversationId)
+		return s.conversationId, nil
+	}
+	resp, err := s.conversation.CreateRequest().WithMetaData(s.conversationMetaData(nil)).Do(ctx)
+	if err != nil {
+		return "", err
+	}
+	if err = resp.Err(); err != nil {
+		return "", err
+	}
+	if resp.Data.Id == "" {
+		return "", errors.New("session: empty conversation id")
+	}
+	s.conversationId = resp.Data.Id
+	s.trackConversation(s.conversationId)
+	if err = s.touch(ctx); err != nil {
+		return "", err
+	}
+	return s.conversationId, nil
+}
+
+// conversationMetaData 返回创建会话时使用的附加信息：在 metaData 的基础上写入用户 ID，
+// 使 conversation.DeleteUserConversations 能够找到该用户的会话。
+func (s *Session) conversationMetaData(metaData map[string]any) map[string]any {
+	merged := make(map[string]any, len(metaData)+1)
+	for k, v := range metaData {
+		merged[k] = v
+	}
+	merged[conversation.MetaDataKeyUserId] = s.userId
+	return merged
+}
+
+// touch 将当前会话 ID 写入 store 并刷新过期时间，调用方需持有锁。
+func (s *Session) touch(ctx context.Context) error {
+	if s.store == nil {
+		return nil
+	}
+	if err := s.store.Set(ctx, s.storeKey, s.conversationId, s.storeTTL); err != nil {
+		return fmt.Errorf("session: save conversation to store: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,539 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	chat "github.com/chenmingyong0423/go-coze/chat/v3"
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/chenmingyong0423/go-coze/conversation"
+	"github.com/chenmingyong0423/go-coze/internal/cozetest"
+	"github.com/chenmingyong0423/go-coze/message"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCoze 模拟 Coze 的会话与对话接口，同一个会话中同时只允许进行一个对话。
+type fakeCoze struct {
+	mu            sync.Mutex
+	conversations int
+	chats         int
+	active        map[string]string
+	polls         map[string]int
+	chatBots      map[string]string
+	// 创建会话时携带的初始消息，按会话 ID 保存。
+	seeds map[string][]request.EnterMessage
+	// 会话中的消息，按时间升序排列，由消息相关的接口读写。
+	messages []response.Message
+	// 为 true 时删除消息的接口返回错误。
+	failDelete bool
+	// 为 true 时发起对话的接口返回错误。
+	failChat bool
+	// 每个对话请求携带的消息，按对话 ID 保存。
+	chatMessages map[string][]request.EnterMessage
+	// 已删除的会话 ID。
+	deletedConversations []string
+	// 每个会话最新的 section ID，清除会话上下文时更新。
+	sections map[string]string
+	// 为 true 时流式对话在发送 created 事件后一直保持进行中，直到被取消。
+	holdStream bool
+	// 被取消的对话 ID。
+	canceled []string
+	// 已创建的会话，按创建顺序排列。
+	created []response.Conversation
+	// 为 true 时非流式对话结束后将回答保存到 messages 中。
+	saveAnswers bool
+	// 为 true 时非流式对话以失败状态结束。
+	failAnswers bool
+}
+
+func newFakeCoze(t *testing.T) *fakeCoze {
+	f := &fakeCoze{active: map[string]string{}, polls: map[string]int{}, chatBots: map[string]string{}, seeds: map[string][]request.EnterMessage{}, chatMessages: map[string][]request.EnterMessage{}, sections: map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/conversation/create", f.createConversation)
+	mux.HandleFunc("/v1/conversations", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		cozetest.WriteData(w, conversation.ListData{Conversations: append([]response.Conversation(nil), f.created...)})
+	})
+	mux.HandleFunc("/v3/chat", f.createChat)
+	mux.HandleFunc("/v3/chat/retrieve", f.retrieveChat)
+	mux.HandleFunc("/v3/chat/cancel", f.cancelChat)
+	mux.HandleFunc("/v3/chat/message/list", f.listMessages)
+	mux.HandleFunc("/v1/conversation/message/list", f.listConversationMessages)
+	mux.HandleFunc("/v1/conversation/message/modify", f.modifyMessage)
+	mux.HandleFunc("/v1/conversation/message/delete", f.deleteMessage)
+	mux.HandleFunc("/v1/conversation/message/create", f.createMessage)
+	mux.HandleFunc("/v1/conversations/", func(w http.ResponseWriter, r *http.Request) {
+		conversationId := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/conversations/"), "/clear")
+		if r.Method == http.MethodDelete {
+			f.mu.Lock()
+			f.deletedConversations = append(f.deletedConversations, conversationId)
+			f.mu.Unlock()
+			cozetest.WriteJSON(w, response.BaseResponse{})
+			return
+		}
+		f.mu.Lock()
+		f.sections[conversationId] = "section-" + conversationId
+		f.mu.Unlock()
+		cozetest.WriteData(w, response.Section{Id: "section-" + conversationId, ConversationId: conversationId})
+	})
+	cozetest.NewServer(t, mux)
+	return f
+}
+
+func (f *fakeCoze) createConversation(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Messages []request.EnterMessage `json:"messages"`
+		MetaData map[string]any         `json:"meta_data"`
+	}
+	_ = jsoniter.NewDecoder(r.Body).Decode(&req)
+
+	f.mu.Lock()
+	f.conversations++
+	id := fmt.Sprintf("conversation-%d", f.conversations)
+	f.seeds[id] = req.Messages
+	f.created = append(f.created, response.Conversation{Id: id, MetaData: req.MetaData})
+	f.mu.Unlock()
+	cozetest.WriteData(w, response.Conversation{Id: id})
+}
+
+func (f *fakeCoze) createChat(w http.ResponseWriter, r *http.Request) {
+	var req chat.CreateRequest
+	_ = jsoniter.NewDecoder(r.Body).Decode(&req)
+	conversationId := r.URL.Query().Get("conversation_id")
+
+	f.mu.Lock()
+	if f.failChat {
+		f.mu.Unlock()
+		cozetest.WriteJSON(w, response.BaseResponse{Code: 5000, Msg: "internal error"})
+		return
+	}
+	if _, ok := f.active[conversationId]; ok {
+		f.mu.Unlock()
+		cozetest.WriteJSON(w, response.BaseResponse{Code: 4016, Msg: "conversation occupied"})
+		return
+	}
+	f.chats++
+	c := &response.Chat{Id: fmt.Sprintf("chat-%d", f.chats), ConversationId: conversationId, BotId: req.BotID, SectionId: f.sections[conversationId], Status: response.ChatStatusInProgress}
+	f.chatBots[c.Id] = req.BotID
+	f.chatMessages[c.Id] = req.AdditionalMessages
+	if !req.Stream || f.holdStream {
+		f.active[conversationId] = c.Id
+	}
+	hold := f.holdStream
+	f.mu.Unlock()
+
+	if !req.Stream {
+		cozetest.WriteData(w, c)
+		return
+	}
+	message := response.Message{Id: "message-" + c.Id, ConversationId: conversationId, ChatId: c.Id, Role: "assistant", Type: "answer", Content: "你好", ContentType: "text"}
+	chatData, _ := jsoniter.MarshalToString(c)
+	messageData, _ := jsoniter.MarshalToString(message)
+	w.Header().Set("Content-Type", "text/event-stream")
+	_, _ = fmt.Fprintf(w, "event:%s\ndata:%s\n\n", chat.EventChatCreated, chatData)
+	if hold {
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+		return
+	}
+	_, _ = fmt.Fprintf(w, "event:%s\ndata:%s\n\n", chat.EventMessageDelta, messageData)
+	_, _ = fmt.Fprintf(w, "event:%s\ndata:%s\n\n", chat.EventMessageCompleted, messageData)
+	c.Status = response.ChatStatusCompleted
+	chatData, _ = jsoniter.MarshalToString(c)
+	_, _ = fmt.Fprintf(w, "event:%s\ndata:%s\n\n", chat.EventChatCompleted, chatData)
+	_, _ = fmt.Fprint(w, "event:done\ndata:\"[DONE]\"\n\n")
+}
+
+func (f *fakeCoze) cancelChat(w http.ResponseWriter, r *http.Request) {
+	conversationId, chatId := r.URL.Query().Get("conversation_id"), r.URL.Query().Get("chat_id")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.canceled = append(f.canceled, chatId)
+	delete(f.active, conversationId)
+	cozetest.WriteData(w, &response.Chat{Id: chatId, ConversationId: conversationId, Status: response.ChatStatusCanceled})
+}
+
+func (f *fakeCoze) retrieveChat(w http.ResponseWriter, r *http.Request) {
+	conversationId, chatId := r.URL.Query().Get("conversation_id"), r.URL.Query().Get("chat_id")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.polls[chatId]++
+	c := &response.Chat{Id: chatId, ConversationId: conversationId, SectionId: f.sections[conversationId], Status: response.ChatStatusInProgress}
+	if f.polls[chatId] >= 2 {
+		c.Status = response.ChatStatusCompleted
+		c.Usage.TokenCount = 100
+		delete(f.active, conversationId)
+		if f.saveAnswers {
+			f.messages = append(f.messages, response.Message{Id: "message-" + chatId, ConversationId: conversationId, ChatId: chatId, Role: "assistant", Type: "answer", Content: "你好", ContentType: "text"})
+		}
+		if f.failAnswers {
+			c.Status, c.LastError = response.ChatStatusFailed, response.LastError{Code: 5000, Msg: "internal error"}
+		}
+	}
+	cozetest.WriteData(w, c)
+}
+
+func (f *fakeCoze) listMessages(w http.ResponseWriter, r *http.Request) {
+	conversationId, chatId := r.URL.Query().Get("conversation_id"), r.URL.Query().Get("chat_id")
+	f.mu.Lock()
+	answer := "你好"
+	if f.chatBots[chatId] == "summariser" {
+		answer = "摘要"
+	}
+	f.mu.Unlock()
+	cozetest.WriteData(w, []response.Message{
+		{Id: "message-" + chatId, ConversationId: conversationId, ChatId: chatId, Role: "assistant", Type: "answer", Content: answer, ContentType: "text"},
+		{Id: "follow-up-" + chatId, ConversationId: conversationId, ChatId: chatId, Role: "assistant", Type: "follow_up", Content: "还有什么问题？", ContentType: "text"},
+	})
+}
+
+func (f *fakeCoze) listConversationMessages(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Order string `json:"order"`
+	}
+	_ = jsoniter.NewDecoder(r.Body).Decode(&req)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// 默认倒序返回
+	messages := make([]response.Message, 0, len(f.messages))
+	for i := len(f.messages) - 1; i >= 0; i-- {
+		messages = append(messages, f.messages[i])
+	}
+	if req.Order == message.OrderAsc {
+		messages = append(messages[:0], f.messages...)
+	}
+	cozetest.WriteJSON(w, map[string]any{"code": 0, "data": messages})
+}
+
+func (f *fakeCoze) modifyMessage(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Content     string `json:"content"`
+		ContentType string `json:"content_type"`
+	}
+	_ = jsoniter.NewDecoder(r.Body).Decode(&req)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.messages {
+		if f.messages[i].Id == r.URL.Query().Get("message_id") {
+			f.messages[i].Content, f.messages[i].ContentType = req.Content, req.ContentType
+			cozetest.WriteJSON(w, map[string]any{"code": 0, "message": f.messages[i]})
+			return
+		}
+	}
+	cozetest.WriteJSON(w, response.BaseResponse{Code: 4000, Msg: "message not found"})
+}
+
+func (f *fakeCoze) deleteMessage(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failDelete {
+		cozetest.WriteJSON(w, response.BaseResponse{Code: 5000, Msg: "internal error"})
+		return
+	}
+	for i := range f.messages {
+		if f.messages[i].Id == r.URL.Query().Get("message_id") {
+			deleted := f.messages[i]
+			f.messages = append(f.messages[:i], f.messages[i+1:]...)
+			cozetest.WriteData(w, deleted)
+			return
+		}
+	}
+	cozetest.WriteJSON(w, response.BaseResponse{Code: 4000, Msg: "message not found"})
+}
+
+func (f *fakeCoze) createMessage(w http.ResponseWriter, r *http.Request) {
+	var m response.Message
+	_ = jsoniter.NewDecoder(r.Body).Decode(&m)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	m.Id = fmt.Sprintf("recreated-%d", len(f.messages))
+	f.messages = append(f.messages, m)
+	cozetest.WriteData(w, m)
+}
+
+func userMessage(content string) request.EnterMessage {
+	return request.NewEnterMessageBuilder().Role("user").Content(content).ContentType("text").Build()
+}
+
+func TestSession_Send(t *testing.T) {
+	f := newFakeCoze(t)
+	s := NewSession("token", "user", "bot").WithPollInterval(time.Millisecond)
+
+	turn, err := s.Send(context.Background(), userMessage("你好"))
+	require.NoError(t, err)
+	require.Equal(t, response.ChatStatusCompleted, turn.Chat.Status)
+	require.Equal(t, "你好", turn.Answer())
+	require.Equal(t, "conversation-1", s.ConversationId())
+
+	_, err = s.Send(context.Background(), userMessage("再见"))
+	require.NoError(t, err)
+	require.Equal(t, 1, f.conversations)
+	require.Equal(t, []string{"chat-1", "chat-2"}, s.ChatIds())
+
+	history := s.History()
+	require.Len(t, history, 6)
+	require.Equal(t, "user", history[0].Role)
+	require.Equal(t, "你好", history[0].Content)
+	require.Equal(t, "chat-1", history[0].ChatId)
+	require.Equal(t, "user", history[3].Role)
+	require.Equal(t, "再见", history[3].Content)
+}
+
+func TestSession_SendConcurrent(t *testing.T) {
+	f := newFakeCoze(t)
+	s := NewSession("token", "user", "bot").WithPollInterval(time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := s.Send(context.Background(), userMessage(fmt.Sprint(i)))
+			require.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+	require.Equal(t, 1, f.conversations)
+	require.Len(t, s.ChatIds(), 8)
+}
+
+func TestSession_SendStream(t *testing.T) {
+	newFakeCoze(t)
+	s := NewSession("token", "user", "bot").WithConversationId("conversation-existing")
+
+	respChan, errChan := s.SendStream(context.Background(), userMessage("你好"))
+	var events []string
+	for respChan != nil || errChan != nil {
+		select {
+		case resp, ok := <-respChan:
+			if !ok {
+				respChan = nil
+				continue
+			}
+			events = append(events, resp.Event)
+		case err, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			require.NoError(t, err)
+		}
+	}
+	require.Equal(t, []string{chat.EventChatCreated, chat.EventMessageDelta, chat.EventMessageCompleted, chat.EventChatCompleted}, events)
+	require.Equal(t, "conversation-existing", s.ConversationId())
+	require.Equal(t, []string{"chat-1"}, s.ChatIds())
+
+	history := s.History()
+	require.Len(t, history, 2)
+	require.Equal(t, "user", history[0].Role)
+	require.Equal(t, "message-chat-1", history[1].Id)
+}
+
+func TestSession_SendStreamAbandoned(t *testing.T) {
+	f := newFakeCoze(t)
+	f.holdStream = true
+	s := NewSession("token", "user", "bot").WithConversationId("conversation-existing").WithPollInterval(time.Millisecond)
+
+	// 读取第一个事件后取消 ctx 并停止读取，Session 不能因此一直被占用。
+	ctx, cancel := context.WithCancel(context.Background())
+	respChan, _ := s.SendStream(ctx, userMessage("你好"))
+	resp := <-respChan
+	require.Equal(t, chat.EventChatCreated, resp.Event)
+	cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.Send(context.Background(), userMessage("在吗"))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("session is still locked by the abandoned stream")
+	}
+	require.Len(t, s.ChatIds(), 2)
+	// 被放弃的对话在下一轮对话开始前已被取消，否则会话仍被占用，下一轮对话会失败。
+	require.Equal(t, []string{"chat-1"}, f.canceled)
+}
+
+func TestSession_SendCanceled(t *testing.T) {
+	newFakeCoze(t)
+	s := NewSession("token", "user", "bot").WithPollInterval(time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := s.Send(ctx, userMessage("你好"))
+	require.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestSession_WithStore(t *testing.T) {
+	f := newFakeCoze(t)
+	clock := &fakeClock{now: time.Now()}
+	store := NewMemoryStore()
+	store.now = clock.Now
+
+	_, err := NewSession("token", "user", "bot").WithPollInterval(time.Millisecond).WithStore(store, time.Hour).
+		Send(context.Background(), userMessage("你好"))
+	require.NoError(t, err)
+	id, ok, err := store.Get(context.Background(), StoreKey("bot", "user"))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "conversation-1", id)
+
+	// 模拟服务重启后，同一用户继续之前的会话。
+	s := NewSession("token", "user", "bot").WithPollInterval(time.Millisecond).WithStore(store, time.Hour)
+	_, err = s.Send(context.Background(), userMessage("还在吗"))
+	require.NoError(t, err)
+	require.Equal(t, "conversation-1", s.ConversationId())
+	require.Equal(t, 1, f.conversations)
+
+	// 过期后开始新的会话。
+	clock.now = clock.now.Add(2 * time.Hour)
+	_, err = s.Send(context.Background(), userMessage("好久不见"))
+	require.NoError(t, err)
+	require.Equal(t, "conversation-2", s.ConversationId())
+	require.Equal(t, 2, f.conversations)
+}
+
+func TestSession_Rollover(t *testing.T) {
+	f := newFakeCoze(t)
+	var rolled []string
+	s := NewSession("token", "user", "bot").WithPollInterval(time.Millisecond).WithRollover(RolloverPolicy{
+		MaxMessages:     5,
+		SummariserBotId: "summariser",
+		OnRollover: func(oldConversationId, newConversationId, summary string) {
+			rolled = append(rolled, oldConversationId, newConversationId, summary)
+		},
+	})
+
+	// 每轮对话产生 3 条消息：用户消息、回答和建议问题。
+	_, err := s.Send(context.Background(), userMessage("1"))
+	require.NoError(t, err)
+	_, err = s.Send(context.Background(), userMessage("2"))
+	require.NoError(t, err)
+	require.Equal(t, "conversation-1", s.ConversationId())
+	require.Empty(t, rolled)
+
+	// 第三轮对话开始前消息数已超过阈值，先在独立会话中生成摘要，再切换到新的会话。
+	turn, err := s.Send(context.Background(), userMessage("3"))
+	require.NoError(t, err)
+	require.Equal(t, "conversation-3", s.ConversationId())
+	require.Equal(t, "conversation-3", turn.Chat.ConversationId)
+	require.Equal(t, []string{"conversation-1", "conversation-3", "摘要"}, rolled)
+	require.Equal(t, []request.EnterMessage{{Role: "assistant", Type: "answer", Content: "摘要", ContentType: "text"}}, f.seeds["conversation-3"])
+	require.Equal(t, "summariser", f.chatBots["chat-3"])
+	require.Equal(t, []string{"conversation-2"}, f.deletedConversations)
+
+	_, err = s.Send(context.Background(), userMessage("4"))
+	require.NoError(t, err)
+	require.Equal(t, "conversation-3", s.ConversationId())
+}
+
+func TestSession_DeleteUserConversations(t *testing.T) {
+	f := newFakeCoze(t)
+	s := NewSession("token", "user", "bot").WithPollInterval(time.Millisecond).WithRollover(RolloverPolicy{
+		MaxMessages:     3,
+		SummariserBotId: "summariser",
+		MetaData:        map[string]any{"tenant": "a", conversation.MetaDataKeyUserId: "other"},
+	})
+	_, err := s.Send(context.Background(), userMessage("1"))
+	require.NoError(t, err)
+	_, err = s.Send(context.Background(), userMessage("2"))
+	require.NoError(t, err)
+	require.Equal(t, "conversation-3", s.ConversationId())
+
+	// Session 创建的会话和切换后的会话都带有用户 ID，按用户删除时能够被找到。
+	require.Equal(t, map[string]any{"tenant": "a", conversation.MetaDataKeyUserId: "user"}, f.created[2].MetaData)
+	deleted, err := conversation.NewConversation("token").DeleteUserConversations(context.Background(), "bot", "user", 2)
+	require.NoError(t, err)
+	require.Contains(t, deleted, "conversation-1")
+	require.Contains(t, deleted, "conversation-3")
+}
+
+func TestSession_RolloverResumed(t *testing.T) {
+	f := newFakeCoze(t)
+	// 恢复的会话中已有之前的消息，这些消息不在 Session 的内存历史中。
+	f.messages = []response.Message{
+		{Id: "q0", Role: "user", Type: "question", Content: "之前的问题", ContentType: "text"},
+		{Id: "a0", Role: "assistant", Type: "answer", Content: "之前的回答", ContentType: "text"},
+		{Id: "v0", Role: "assistant", Type: "verbose", Content: "{}", ContentType: "text"},
+	}
+	s := NewSession("token", "user", "bot").WithPollInterval(time.Millisecond).WithConversationId("conversation-existing").
+		WithRollover(RolloverPolicy{MaxMessages: 3, SummariserBotId: "summariser"})
+
+	_, err := s.Send(context.Background(), userMessage("1"))
+	require.NoError(t, err)
+	_, err = s.Send(context.Background(), userMessage("2"))
+	require.NoError(t, err)
+	require.Equal(t, "conversation-2", s.ConversationId())
+
+	require.Equal(t, "summariser", f.chatBots["chat-2"])
+	summariserMessages := f.chatMessages["chat-2"]
+	require.Len(t, summariserMessages, 3)
+	require.Equal(t, "之前的问题", summariserMessages[0].Content)
+	require.Equal(t, "之前的回答", summariserMessages[1].Content)
+	require.Equal(t, defaultSummaryPrompt, summariserMessages[2].Content)
+	require.Equal(t, []string{"conversation-1"}, f.deletedConversations)
+}
+
+func TestSession_RolloverByTokens(t *testing.T) {
+	newFakeCoze(t)
+	s := NewSession("token", "user", "bot").WithPollInterval(time.Millisecond).WithRollover(RolloverPolicy{
+		MaxTokens:       100,
+		SummariserBotId: "summariser",
+	})
+	_, err := s.Send(context.Background(), userMessage("1"))
+	require.NoError(t, err)
+	require.Equal(t, "conversation-1", s.ConversationId())
+	_, err = s.Send(context.Background(), userMessage("2"))
+	require.NoError(t, err)
+	require.Equal(t, "conversation-3", s.ConversationId())
+}
+
+func TestSession_NewTopic(t *testing.T) {
+	f := newFakeCoze(t)
+	s := NewSession("token", "user", "bot").WithPollInterval(time.Millisecond)
+	require.Empty(t, s.SectionId())
+
+	sectionId, err := s.NewTopic(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "section-conversation-1", sectionId)
+	require.Equal(t, sectionId, s.SectionId())
+	require.Equal(t, "conversation-1", s.ConversationId())
+
+	// 对话总是在会话最新的 section 中进行。
+	turn, err := s.Send(context.Background(), userMessage("新话题"))
+	require.NoError(t, err)
+	require.Equal(t, sectionId, turn.Chat.SectionId)
+
+	// 其他客户端清除上下文后，Session 会从对话的响应中得知新的 section。
+	f.mu.Lock()
+	f.sections["conversation-1"] = "section-other"
+	f.mu.Unlock()
+	_, err = s.Send(context.Background(), userMessage("继续"))
+	require.NoError(t, err)
+	require.Equal(t, "section-other", s.SectionId())
+}
@@ -0,0 +1,167 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/chenmingyong0423/go-coze/internal/jsonfile"
+)
+
+// ConversationStore 保存终端用户与会话 ID 之间的映射，使用户在服务重启或多副本部署时仍能继续之前的会话。
+// 实现必须是并发安全的。
+type ConversationStore interface {
+	// Get 返回 key 对应的会话 ID，不存在或已过期时 ok 为 false。
+	Get(ctx context.Context, key string) (conversationId string, ok bool, err error)
+	// Set 保存 key 对应的会话 ID，ttl 为 0 时永不过期。
+	Set(ctx context.Context, key string, conversationId string, ttl time.Duration) error
+	// Delete 删除 key 对应的会话 ID。
+	Delete(ctx context.Context, key string) error
+}
+
+// StoreKey 返回 Bot 和用户对应的存储键。
+func StoreKey(botId, userId string) string {
+	return botId + ":" + userId
+}
+
+type storeEntry struct {
+	ConversationId string `json:"conversation_id"`
+	// 过期时间，Unix 纳秒时间戳，0 表示永不过期。
+	ExpiresAt int64 `json:"expires_at,omitempty"`
+}
+
+func newStoreEntry(conversationId string, ttl time.Duration, now time.Time) storeEntry {
+	entry := storeEntry{ConversationId: conversationId}
+	if ttl > 0 {
+		entry.ExpiresAt = now.Add(ttl).UnixNano()
+	}
+	return entry
+}
+
+func (e storeEntry) expired(now time.Time) bool {
+	return e.ExpiresAt != 0 && now.UnixNano() >= e.ExpiresAt
+}
+
+// MemoryStore 是基于内存的 ConversationStore，进程重启后数据会丢失。
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]storeEntry
+	now     func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]storeEntry), now: time.Now}
+}
+
+func (m *MemoryStore) Get(_ context.Context, key string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.entries[key]
+	if !ok {
+		return "", false, nil
+	}
+	if entry.expired(m.now()) {
+		delete(m.entries, key)
+		return "", false, nil
+	}
+	return entry.ConversationId, true, nil
+}
+
+func (m *MemoryStore) Set(_ context.Context, key string, conversationId string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = newStoreEntry(conversationId, ttl, m.now())
+	return nil
+}
+
+func (m *MemoryStore) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+	return nil
+}
+
+// FileStore 是基于 JSON 文件的 ConversationStore，数据在服务重启后仍然保留。
+// 每次读写都会重新加载文件，写入时通过临时文件加重命名的方式保证文件完整；
+// 但它不提供跨进程的锁，多个进程同时写入同一个文件时后写入者会覆盖先写入者的修改。
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+	now  func() time.Time
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path, now: time.Now}
+}
+
+func (f *FileStore) Get(_ context.Context, key string) (string, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entries, err := f.load()
+	if err != nil {
+		return "", false, err
+	}
+	entry, ok := entries[key]
+	if !ok || entry.expired(f.now()) {
+		return "", false, nil
+	}
+	return entry.ConversationId, true, nil
+}
+
+func (f *FileStore) Set(_ context.Context, key string, conversationId string, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entries, err := f.load()
+	if err != nil {
+		return err
+	}
+	entries[key] = newStoreEntry(conversationId, ttl, f.now())
+	return f.save(entries)
+}
+
+func (f *FileStore) Delete(_ context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entries, err := f.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[key]; !ok {
+		return nil
+	}
+	delete(entries, key)
+	return f.save(entries)
+}
+
+func (f *FileStore) load() (map[string]storeEntry, error) {
+	entries := make(map[string]storeEntry)
+	if err := jsonfile.Read(f.path, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// save 写入文件，同时清理已过期的记录。
+func (f *FileStore) save(entries map[string]storeEntry) error {
+	now := f.now()
+	for k, entry := range entries {
+		if entry.expired(now) {
+			delete(entries, k)
+		}
+	}
+	return jsonfile.Write(f.path, entries)
+}
@@ -0,0 +1,100 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestConversationStore(t *testing.T) {
+	testCases := []struct {
+		name     string
+		newStore func(t *testing.T, clock *fakeClock) ConversationStore
+	}{
+		{
+			name: "memory",
+			newStore: func(t *testing.T, clock *fakeClock) ConversationStore {
+				store := NewMemoryStore()
+				store.now = clock.Now
+				return store
+			},
+		},
+		{
+			name: "file",
+			newStore: func(t *testing.T, clock *fakeClock) ConversationStore {
+				store := NewFileStore(filepath.Join(t.TempDir(), "conversations.json"))
+				store.now = clock.Now
+				return store
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			clock := &fakeClock{now: time.Unix(1700000000, 0)}
+			store := tc.newStore(t, clock)
+			key := StoreKey("bot", "user")
+
+			_, ok, err := store.Get(ctx, key)
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			require.NoError(t, store.Set(ctx, key, "conversation-1", time.Minute))
+			require.NoError(t, store.Set(ctx, StoreKey("bot", "forever"), "conversation-2", 0))
+			id, ok, err := store.Get(ctx, key)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, "conversation-1", id)
+
+			clock.now = clock.now.Add(time.Minute)
+			_, ok, err = store.Get(ctx, key)
+			require.NoError(t, err)
+			require.False(t, ok)
+			id, ok, err = store.Get(ctx, StoreKey("bot", "forever"))
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, "conversation-2", id)
+
+			require.NoError(t, store.Delete(ctx, StoreKey("bot", "forever")))
+			_, ok, err = store.Get(ctx, StoreKey("bot", "forever"))
+			require.NoError(t, err)
+			require.False(t, ok)
+		})
+	}
+}
+
+func TestFileStore_Persistent(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "conversations.json")
+	require.NoError(t, NewFileStore(path).Set(ctx, "bot:user", "conversation-1", time.Hour))
+
+	id, ok, err := NewFileStore(path).Get(ctx, "bot:user")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "conversation-1", id)
+}
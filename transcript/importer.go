@@ -0,0 +1,159 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transcript
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/chenmingyong0423/go-coze/conversation"
+	"github.com/chenmingyong0423/go-coze/message"
+)
+
+// ImportError 表示会话已创建，但部分消息未能写入。
+type ImportError struct {
+	// 已创建的会话。
+	Conversation response.Conversation
+	// 已写入的消息数量。
+	Imported int
+	Err      error
+}
+
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("transcript: conversation %s created but only %d messages imported: %v", e.Conversation.Id, e.Imported, e.Err)
+}
+
+func (e *ImportError) Unwrap() error {
+	return e.Err
+}
+
+// Importer 将消息导入到新会话中，用于在指定消息处分叉会话或迁移导出的会话记录。
+type Importer struct {
+	authorization string
+	exporter      *Exporter
+}
+
+func NewImporter(authorization string) *Importer {
+	return &Importer{
+		authorization: authorization,
+		exporter:      NewExporter(authorization),
+	}
+}
+
+// EnterMessages 将会话中的消息转换为可重新创建的消息。
+// 只保留用户的问题和 Bot 的回答中 text 和 object_string 类型的内容，工具调用、卡片、推荐问题等中间消息会被忽略。
+func EnterMessages(messages []response.Message) []request.EnterMessage {
+	enterMessages := make([]request.EnterMessage, 0, len(messages))
+	for _, m := range messages {
+		if m.Type != "" && m.Type != response.MessageTypeQuestion && m.Type != response.MessageTypeAnswer {
+			continue
+		}
+		if m.ContentType != request.ContentTypeText && m.ContentType != request.ContentTypeObjectString {
+			continue
+		}
+		if m.Role != request.RoleUser && m.Role != request.RoleAssistant {
+			continue
+		}
+		enterMessage := request.EnterMessage{
+			Role:        m.Role,
+			Content:     m.Content,
+			ContentType: m.ContentType,
+			MetaData:    m.MetaData,
+		}
+		if m.Role == request.RoleAssistant {
+			enterMessage.Type = response.MessageTypeAnswer
+		}
+		enterMessages = append(enterMessages, enterMessage)
+	}
+	return enterMessages
+}
+
+// Import 创建一个携带 messages 的新会话。
+// 前 conversation.MaxCreateMessages 条消息随创建会话的请求一起写入，其余消息逐条通过创建消息的接口追加；
+// 追加失败时返回 *ImportError，其中包含已创建的会话。
+func (i *Importer) Import(ctx context.Context, messages []request.EnterMessage, metaData map[string]any) (*response.Conversation, error) {
+	first := messages
+	if len(first) > conversation.MaxCreateMessages {
+		first = first[:conversation.MaxCreateMessages]
+	}
+	resp, err := conversation.NewConversation(i.authorization).CreateRequest().
+		WithMessages(first...).
+		WithMetaData(metaData).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = resp.Err(); err != nil {
+		return nil, err
+	}
+	created := resp.Data
+
+	m := message.NewMessage(i.authorization, created.Id)
+	for idx := len(first); idx < len(messages); idx++ {
+		enterMessage := messages[idx]
+		req := m.CreateRequest().WithRole(enterMessage.Role)
+		req.Content, req.ContentType, req.Meta = enterMessage.Content, enterMessage.ContentType, enterMessage.MetaData
+		msgResp, err := req.Do(ctx)
+		if err == nil {
+			err = msgResp.Err()
+		}
+		if err != nil {
+			return &created, &ImportError{Conversation: created, Imported: idx, Err: err}
+		}
+	}
+	return &created, nil
+}
+
+// ImportTranscript 将会话记录中的消息导入到新会话中，并沿用原会话的附加信息。
+func (i *Importer) ImportTranscript(ctx context.Context, t *Transcript) (*response.Conversation, error) {
+	return i.Import(ctx, EnterMessages(t.Messages), t.Conversation.MetaData)
+}
+
+// ImportFile 读取 JSON 格式的会话记录文件并导入到新会话中。
+func (i *Importer) ImportFile(ctx context.Context, path string) (*response.Conversation, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	t, err := Read(f)
+	if err != nil {
+		return nil, err
+	}
+	return i.ImportTranscript(ctx, t)
+}
+
+// Fork 复制会话中从开始到 messageId（包含）的消息，创建一个新会话，可用于在该消息处尝试不同的回答。
+func (i *Importer) Fork(ctx context.Context, conversationId, messageId string) (*response.Conversation, error) {
+	t, err := i.exporter.Fetch(ctx, conversationId)
+	if err != nil {
+		return nil, err
+	}
+	end := -1
+	for idx, m := range t.Messages {
+		if m.Id == messageId {
+			end = idx
+			break
+		}
+	}
+	if end < 0 {
+		return nil, fmt.Errorf("transcript: message %s not found in conversation %s", messageId, conversationId)
+	}
+	t.Messages = t.Messages[:end+1]
+	return i.ImportTranscript(ctx, t)
+}
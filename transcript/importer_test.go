@@ -0,0 +1,158 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transcript
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/chenmingyong0423/go-coze/conversation"
+	"github.com/chenmingyong0423/go-coze/internal/cozetest"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeImport 记录创建会话和追加消息的请求。
+type fakeImport struct {
+	created  []request.EnterMessage
+	metaData map[string]any
+	appended []request.EnterMessage
+	// 追加第 failAt 条消息时返回错误，0 表示不失败。
+	failAt int
+}
+
+func (f *fakeImport) register(t *testing.T, mux *http.ServeMux) {
+	mux.HandleFunc("/v1/conversation/create", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []request.EnterMessage `json:"messages"`
+			MetaData map[string]any         `json:"meta_data"`
+		}
+		require.NoError(t, jsoniter.NewDecoder(r.Body).Decode(&req))
+		f.created, f.metaData = req.Messages, req.MetaData
+		cozetest.WriteData(w, response.Conversation{Id: "forked"})
+	})
+	mux.HandleFunc("/v1/conversation/message/create", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "forked", r.URL.Query().Get("conversation_id"))
+		if f.failAt != 0 && len(f.appended)+1 == f.failAt {
+			cozetest.WriteJSON(w, map[string]any{"code": 4000, "msg": "invalid"})
+			return
+		}
+		var req request.EnterMessage
+		require.NoError(t, jsoniter.NewDecoder(r.Body).Decode(&req))
+		f.appended = append(f.appended, req)
+		cozetest.WriteData(w, response.Message{Id: strconv.Itoa(len(f.appended))})
+	})
+}
+
+func textMessages(n int) []request.EnterMessage {
+	messages := make([]request.EnterMessage, 0, n)
+	for i := 0; i < n; i++ {
+		messages = append(messages, request.NewEnterMessageBuilder().Role("user").Content(strconv.Itoa(i)).ContentType("text").Build())
+	}
+	return messages
+}
+
+func TestEnterMessages(t *testing.T) {
+	got := EnterMessages(sampleMessages())
+	require.Equal(t, []request.EnterMessage{
+		{Role: "user", ContentType: "object_string", Content: sampleMessages()[0].Content},
+		{Role: "assistant", Type: "answer", ContentType: "text", Content: "<b>晴</b>", MetaData: map[string]any{"source": "tool"}},
+	}, got)
+}
+
+func TestImporter_Import(t *testing.T) {
+	t.Run("within limit", func(t *testing.T) {
+		fake := &fakeImport{}
+		mux := http.NewServeMux()
+		fake.register(t, mux)
+		cozetest.NewServer(t, mux)
+
+		got, err := NewImporter("token").Import(context.Background(), textMessages(3), map[string]any{"k": "v"})
+		require.NoError(t, err)
+		require.Equal(t, "forked", got.Id)
+		require.Equal(t, textMessages(3), fake.created)
+		require.Equal(t, map[string]any{"k": "v"}, fake.metaData)
+		require.Empty(t, fake.appended)
+	})
+
+	t.Run("chunked", func(t *testing.T) {
+		fake := &fakeImport{}
+		mux := http.NewServeMux()
+		fake.register(t, mux)
+		cozetest.NewServer(t, mux)
+
+		messages := textMessages(conversation.MaxCreateMessages + 3)
+		_, err := NewImporter("token").Import(context.Background(), messages, nil)
+		require.NoError(t, err)
+		require.Equal(t, messages[:conversation.MaxCreateMessages], fake.created)
+		require.Equal(t, messages[conversation.MaxCreateMessages:], fake.appended)
+	})
+
+	t.Run("append failure", func(t *testing.T) {
+		fake := &fakeImport{failAt: 2}
+		mux := http.NewServeMux()
+		fake.register(t, mux)
+		cozetest.NewServer(t, mux)
+
+		got, err := NewImporter("token").Import(context.Background(), textMessages(conversation.MaxCreateMessages+3), nil)
+		var importErr *ImportError
+		require.True(t, errors.As(err, &importErr))
+		require.Equal(t, conversation.MaxCreateMessages+1, importErr.Imported)
+		require.Equal(t, "forked", got.Id)
+		var apiErr *response.ApiError
+		require.True(t, errors.As(err, &apiErr))
+	})
+}
+
+func TestImporter_Fork(t *testing.T) {
+	fake := &fakeImport{}
+	mux := http.NewServeMux()
+	fake.register(t, mux)
+	registerFakeConversation(t, mux, sampleMessages())
+	cozetest.NewServer(t, mux)
+
+	_, err := NewImporter("token").Fork(context.Background(), "conv", "m1")
+	require.NoError(t, err)
+	require.Equal(t, EnterMessages(sampleMessages()[:1]), fake.created)
+	require.Equal(t, map[string]any{"user_id": "u1"}, fake.metaData)
+
+	_, err = NewImporter("token").Fork(context.Background(), "conv", "missing")
+	require.Error(t, err)
+}
+
+func TestImporter_ImportFile(t *testing.T) {
+	fake := &fakeImport{}
+	mux := http.NewServeMux()
+	fake.register(t, mux)
+	cozetest.NewServer(t, mux)
+
+	path := filepath.Join(t.TempDir(), "transcript.json")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	tr := &Transcript{Conversation: response.Conversation{Id: "conv"}, Messages: sampleMessages()}
+	require.NoError(t, tr.WriteJSON(f))
+	require.NoError(t, f.Close())
+
+	_, err = NewImporter("token").ImportFile(context.Background(), path)
+	require.NoError(t, err)
+	require.Equal(t, EnterMessages(sampleMessages()), fake.created)
+}
@@ -0,0 +1,312 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transcript
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/chenmingyong0423/go-coze/common/response"
+	jsoniter "github.com/json-iterator/go"
+)
+
+const timeLayout = "2006-01-02 15:04:05 MST"
+
+// part 是消息内容中可渲染的一个片段。
+type part struct {
+	// text 为普通文本；code 为代码块（工具调用、卡片等 JSON 内容）；link 为文件、图片或音频。
+	Kind  string
+	Label string
+	Text  string
+	Url   string
+}
+
+// view 是渲染单条消息所需的数据。
+type view struct {
+	Id       string
+	Role     string
+	Type     string
+	ChatId   string
+	Time     string
+	Parts    []part
+	MetaData []metaEntry
+}
+
+type metaEntry struct {
+	Key   string
+	Value string
+}
+
+func formatTime(ts int64, location *time.Location) string {
+	if ts == 0 {
+		return ""
+	}
+	return time.Unix(ts, 0).In(location).Format(timeLayout)
+}
+
+// sortedMetaData 按键排序元数据，保证输出稳定。
+func sortedMetaData(metaData map[string]any) []metaEntry {
+	entries := make([]metaEntry, 0, len(metaData))
+	for k, v := range metaData {
+		value, ok := v.(string)
+		if !ok {
+			// 与标准库一致对 map 的键排序，保证输出稳定。
+			value, _ = jsoniter.ConfigCompatibleWithStandardLibrary.MarshalToString(v)
+		}
+		entries = append(entries, metaEntry{Key: k, Value: value})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
+
+// prettyJSON 缩进 JSON 文本并保留原有的键顺序，非 JSON 时原样返回。jsoniter 没有提供 Indent，因此使用标准库。
+func prettyJSON(s string) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(s), "", "  "); err != nil {
+		return s
+	}
+	return buf.String()
+}
+
+func messageParts(m response.Message) []part {
+	switch m.Type {
+	case response.MessageTypeFunctionCall:
+		return []part{{Kind: "code", Label: "tool call", Text: prettyJSON(m.Content)}}
+	case response.MessageTypeToolOutput, response.MessageTypeToolResponse:
+		return []part{{Kind: "code", Label: "tool output", Text: prettyJSON(m.Content)}}
+	}
+	switch m.ContentType {
+	case request.ContentTypeObjectString:
+		objectStrings, err := m.ObjectStrings()
+		if err != nil {
+			return []part{{Kind: "code", Label: "object_string", Text: m.Content}}
+		}
+		parts := make([]part, 0, len(objectStrings))
+		for _, o := range objectStrings {
+			if o.Type == request.ObjectStringTypeText {
+				parts = append(parts, part{Kind: "text", Text: o.Text})
+				continue
+			}
+			p := part{Kind: "link", Label: o.Type, Url: o.FileUrl, Text: o.FileId}
+			if p.Text == "" {
+				p.Text = o.FileUrl
+			}
+			parts = append(parts, p)
+		}
+		return parts
+	case request.ContentTypeCard:
+		return []part{{Kind: "code", Label: "card", Text: prettyJSON(m.Content)}}
+	default:
+		return []part{{Kind: "text", Text: m.Content}}
+	}
+}
+
+func (t *Transcript) views(location *time.Location) []view {
+	views := make([]view, 0, len(t.Messages))
+	for _, m := range t.Messages {
+		views = append(views, view{
+			Id:       m.Id,
+			Role:     m.Role,
+			Type:     m.Type,
+			ChatId:   m.ChatId,
+			Time:     formatTime(m.CreateTime, location),
+			Parts:    messageParts(m),
+			MetaData: sortedMetaData(m.MetaData),
+		})
+	}
+	return views
+}
+
+// fence 返回不会与 text 中反引号冲突的代码块围栏。
+func fence(text string) string {
+	f := "```"
+	for strings.Contains(text, f) {
+		f += "`"
+	}
+	return f
+}
+
+// escapeMarkdown 转义会被解析为标题、setext 标题下划线、代码块围栏、HTML 注释或链接的内容，
+// 避免消息内容伪造出导出文件的结构。
+func escapeMarkdown(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		if len(line)-len(trimmed) > 3 {
+			// 缩进 4 个空格以上的行是代码块，不会被解析。
+			continue
+		}
+		indent, escaped := line[:len(line)-len(trimmed)], escapeBrackets(trimmed)
+		if isSetextUnderline(escaped) {
+			escaped = "\\" + escaped
+		} else {
+			for _, prefix := range []string{"#", "```", "~~~", "<!--"} {
+				if strings.HasPrefix(escaped, prefix) {
+					escaped = "\\" + escaped
+					break
+				}
+			}
+		}
+		lines[i] = indent + escaped
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isSetextUnderline 判断该行是否只由 = 或只由 - 组成（允许夹杂空格），这样的行会把上一行变成标题或分隔线。
+func isSetextUnderline(line string) bool {
+	marks := strings.Replace(line, " ", "", -1)
+	if marks == "" {
+		return false
+	}
+	return strings.Trim(marks, "=") == "" || strings.Trim(marks, "-") == ""
+}
+
+// escapeBrackets 转义未被转义的方括号，避免文本被解析为链接或图片。
+func escapeBrackets(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if (c == '[' || c == ']') && (i == 0 || text[i-1] != '\\') {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+func (t *Transcript) writeMarkdown(w io.Writer, location *time.Location) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# Conversation %s\n\n", t.Conversation.Id)
+	fmt.Fprintf(bw, "- Created at: %s\n", formatTime(t.Conversation.CreatedAt, location))
+	fmt.Fprintf(bw, "- Exported at: %s\n", formatTime(t.ExportedAt, location))
+	fmt.Fprintf(bw, "- Messages: %d\n", len(t.Messages))
+	for _, e := range sortedMetaData(t.Conversation.MetaData) {
+		fmt.Fprintf(bw, "- Meta `%s`: %s\n", e.Key, escapeMarkdown(e.Value))
+	}
+
+	for _, v := range t.views(location) {
+		fmt.Fprintf(bw, "\n## %s", v.Role)
+		if v.Type != "" {
+			fmt.Fprintf(bw, " (%s)", v.Type)
+		}
+		fmt.Fprintf(bw, " · %s\n\n", v.Time)
+		fmt.Fprintf(bw, "<!-- message_id: %s", v.Id)
+		if v.ChatId != "" {
+			fmt.Fprintf(bw, " chat_id: %s", v.ChatId)
+		}
+		fmt.Fprint(bw, " -->\n\n")
+		for _, p := range v.Parts {
+			switch p.Kind {
+			case "code":
+				f := fence(p.Text)
+				fmt.Fprintf(bw, "%s:\n\n%sjson\n%s\n%s\n\n", p.Label, f, p.Text, f)
+			case "link":
+				if p.Label == request.ObjectStringTypeImage && p.Url != "" {
+					fmt.Fprintf(bw, "![%s](%s)\n\n", escapeBrackets(p.Text), p.Url)
+				} else if p.Url != "" {
+					fmt.Fprintf(bw, "[%s: %s](%s)\n\n", p.Label, escapeBrackets(p.Text), p.Url)
+				} else {
+					fmt.Fprintf(bw, "[%s: %s]\n\n", p.Label, escapeBrackets(p.Text))
+				}
+			default:
+				fmt.Fprintf(bw, "%s\n\n", escapeMarkdown(p.Text))
+			}
+		}
+		for _, e := range v.MetaData {
+			fmt.Fprintf(bw, "- Meta `%s`: %s\n", e.Key, escapeMarkdown(e.Value))
+		}
+	}
+	return bw.Flush()
+}
+
+var htmlTemplate = template.Must(template.New("transcript").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Conversation {{.Conversation.Id}}</title>
+<style>
+body{font-family:sans-serif;max-width:960px;margin:2em auto;color:#222}
+.message{border:1px solid #ddd;border-radius:6px;padding:.5em 1em;margin:1em 0}
+.user{background:#f3f7ff}.assistant{background:#f8f8f8}
+.header{color:#666;font-size:.9em}
+pre{background:#272822;color:#f8f8f2;padding:.5em;overflow:auto}
+.meta{font-size:.85em;color:#555}
+</style>
+</head>
+<body>
+<h1>Conversation {{.Conversation.Id}}</h1>
+<ul class="meta">
+<li>Created at: {{.CreatedAt}}</li>
+<li>Exported at: {{.ExportedAt}}</li>
+<li>Messages: {{len .Views}}</li>
+{{- range .MetaData}}
+<li>Meta <code>{{.Key}}</code>: {{.Value}}</li>
+{{- end}}
+</ul>
+{{- range .Views}}
+<div class="message {{.Role}}" id="{{.Id}}">
+<div class="header"><strong>{{.Role}}</strong>{{if .Type}} ({{.Type}}){{end}} · {{.Time}}{{if .ChatId}} · chat {{.ChatId}}{{end}}</div>
+{{- range .Parts}}
+{{- if eq .Kind "code"}}
+<div>{{.Label}}:</div>
+<pre>{{.Text}}</pre>
+{{- else if eq .Kind "link"}}
+{{- if and (eq .Label "image") .Url}}
+<p><img src="{{.Url}}" alt="{{.Text}}" style="max-width:100%"></p>
+{{- else if .Url}}
+<p>[{{.Label}}] <a href="{{.Url}}">{{.Text}}</a></p>
+{{- else}}
+<p>[{{.Label}}] {{.Text}}</p>
+{{- end}}
+{{- else}}
+<p style="white-space:pre-wrap">{{.Text}}</p>
+{{- end}}
+{{- end}}
+{{- if .MetaData}}
+<ul class="meta">
+{{- range .MetaData}}
+<li>Meta <code>{{.Key}}</code>: {{.Value}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</div>
+{{- end}}
+</body>
+</html>
+`))
+
+func (t *Transcript) writeHTML(w io.Writer, location *time.Location) error {
+	return htmlTemplate.Execute(w, struct {
+		Conversation response.Conversation
+		CreatedAt    string
+		ExportedAt   string
+		MetaData     []metaEntry
+		Views        []view
+	}{
+		Conversation: t.Conversation,
+		CreatedAt:    formatTime(t.Conversation.CreatedAt, location),
+		ExportedAt:   formatTime(t.ExportedAt, location),
+		MetaData:     sortedMetaData(t.Conversation.MetaData),
+		Views:        t.views(location),
+	})
+}
@@ -0,0 +1,137 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package transcript 用于导出会话的完整记录（支持 JSON、Markdown 和 HTML 三种格式），
+// 以及将会话记录导入或分叉到新会话中。
+package transcript
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/chenmingyong0423/go-coze/conversation"
+	"github.com/chenmingyong0423/go-coze/message"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Format 表示导出的格式。
+type Format string
+
+const (
+	FormatJSON     Format = "json"
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+)
+
+// Transcript 是一个会话的完整记录，消息按时间升序排列。
+type Transcript struct {
+	Conversation response.Conversation `json:"conversation"`
+	Messages     []response.Message    `json:"messages"`
+	// 导出时间，Unix 秒级时间戳。
+	ExportedAt int64 `json:"exported_at"`
+}
+
+// Exporter 读取会话及其全部消息并导出为会话记录。
+type Exporter struct {
+	conversation  *conversation.Conversation
+	authorization string
+	location      *time.Location
+}
+
+func NewExporter(authorization string) *Exporter {
+	return &Exporter{
+		conversation:  conversation.NewConversation(authorization),
+		authorization: authorization,
+		location:      time.Local,
+	}
+}
+
+// WithLocation 设置 Markdown 和 HTML 中时间的显示时区，默认为本地时区。
+func (e *Exporter) WithLocation(location *time.Location) *Exporter {
+	e.location = location
+	return e
+}
+
+// Fetch 查询会话信息，并按时间升序遍历会话中的全部消息。
+func (e *Exporter) Fetch(ctx context.Context, conversationId string) (*Transcript, error) {
+	resp, err := e.conversation.RetrieveRequest().Do(ctx, conversationId)
+	if err != nil {
+		return nil, err
+	}
+	if err = resp.Err(); err != nil {
+		return nil, err
+	}
+
+	messages, err := message.NewMessage(e.authorization, conversationId).ListRequest().WithOrder(message.OrderAsc).All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].CreateTime < messages[j].CreateTime
+	})
+	return &Transcript{
+		Conversation: resp.Data,
+		Messages:     messages,
+		ExportedAt:   time.Now().Unix(),
+	}, nil
+}
+
+// Export 查询会话的完整记录并以 format 格式写入 w。
+func (e *Exporter) Export(ctx context.Context, conversationId string, format Format, w io.Writer) error {
+	t, err := e.Fetch(ctx, conversationId)
+	if err != nil {
+		return err
+	}
+	return t.Write(w, format, e.location)
+}
+
+// Write 以 format 格式写入会话记录，location 为 Markdown 和 HTML 中时间的显示时区，为 nil 时使用 UTC。
+func (t *Transcript) Write(w io.Writer, format Format, location *time.Location) error {
+	if location == nil {
+		location = time.UTC
+	}
+	switch format {
+	case FormatJSON:
+		return t.WriteJSON(w)
+	case FormatMarkdown:
+		return t.writeMarkdown(w, location)
+	case FormatHTML:
+		return t.writeHTML(w, location)
+	default:
+		return fmt.Errorf("transcript: unsupported format %q", format)
+	}
+}
+
+// WriteJSON 以带缩进的 JSON 格式写入会话记录，可通过 Read 读回。
+func (t *Transcript) WriteJSON(w io.Writer) error {
+	data, err := jsoniter.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// Read 读取 JSON 格式的会话记录。
+func Read(r io.Reader) (*Transcript, error) {
+	t := new(Transcript)
+	if err := jsoniter.NewDecoder(r).Decode(t); err != nil {
+		return nil, fmt.Errorf("transcript: malformed json transcript: %w", err)
+	}
+	return t, nil
+}
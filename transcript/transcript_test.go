@@ -0,0 +1,172 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transcript
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/chenmingyong0423/go-coze/internal/cozetest"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func registerFakeConversation(t *testing.T, mux *http.ServeMux, messages []response.Message) {
+	mux.HandleFunc("/v1/conversation/retrieve", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "conv", r.URL.Query().Get("conversation_id"))
+		cozetest.WriteData(w, response.Conversation{Id: "conv", CreatedAt: 1700000000, MetaData: map[string]any{"user_id": "u1"}})
+	})
+	mux.HandleFunc("/v1/conversation/message/list", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			AfterId string `json:"after_id"`
+		}
+		require.NoError(t, jsoniter.NewDecoder(r.Body).Decode(&req))
+		// 每页返回两条消息
+		start := 0
+		if req.AfterId != "" {
+			start, _ = strconv.Atoi(req.AfterId)
+		}
+		end := start + 2
+		if end > len(messages) {
+			end = len(messages)
+		}
+		cozetest.WriteJSON(w, map[string]any{
+			"code":     0,
+			"data":     messages[start:end],
+			"last_id":  strconv.Itoa(end),
+			"has_more": end < len(messages),
+		})
+	})
+}
+
+func sampleMessages() []response.Message {
+	return []response.Message{
+		{Id: "m1", Role: "user", Type: "question", ContentType: "object_string", CreateTime: 1700000001,
+			Content: `[{"type":"text","text":"看看这张图"},{"type":"image","file_url":"https://example.com/a.png"},{"type":"file","file_id":"f1"}]`},
+		{Id: "m2", Role: "assistant", Type: "function_call", ContentType: "text", CreateTime: 1700000002,
+			Content: `{"name":"weather","arguments":{"city":"北京"}}`},
+		{Id: "m3", Role: "assistant", Type: "tool_output", ContentType: "text", CreateTime: 1700000003,
+			Content: `{"temperature":20}`},
+		{Id: "m4", Role: "assistant", Type: "answer", ContentType: "text", CreateTime: 1700000004,
+			Content: "<b>晴</b>", MetaData: map[string]any{"source": "tool"}},
+	}
+}
+
+func TestExporter_Fetch(t *testing.T) {
+	mux := http.NewServeMux()
+	registerFakeConversation(t, mux, sampleMessages())
+	cozetest.NewServer(t, mux)
+
+	got, err := NewExporter("token").Fetch(context.Background(), "conv")
+	require.NoError(t, err)
+	require.Equal(t, "conv", got.Conversation.Id)
+	require.Equal(t, sampleMessages(), got.Messages)
+	require.NotZero(t, got.ExportedAt)
+}
+
+func TestTranscript_Write(t *testing.T) {
+	tr := &Transcript{
+		Conversation: response.Conversation{Id: "conv", CreatedAt: 1700000000, MetaData: map[string]any{"user_id": "u1"}},
+		Messages:     sampleMessages(),
+		ExportedAt:   1700000100,
+	}
+
+	t.Run("json round trip", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, tr.Write(&buf, FormatJSON, nil))
+		got, err := Read(&buf)
+		require.NoError(t, err)
+		require.Equal(t, tr, got)
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, tr.Write(&buf, FormatMarkdown, time.UTC))
+		out := buf.String()
+		require.Contains(t, out, "# Conversation conv")
+		require.Contains(t, out, "- Meta `user_id`: u1")
+		require.Contains(t, out, "## user (question) · 2023-11-14 22:13:21 UTC")
+		require.Contains(t, out, "看看这张图")
+		require.Contains(t, out, "![https://example.com/a.png](https://example.com/a.png)")
+		require.Contains(t, out, "[file: f1]")
+		require.Contains(t, out, "tool call:\n\n```json\n{\n  \"name\": \"weather\",\n  \"arguments\": {\n    \"city\": \"北京\"\n  }\n}\n```")
+		require.Contains(t, out, "tool output:")
+		require.Contains(t, out, "- Meta `source`: tool")
+	})
+
+	t.Run("markdown escapes message structure", func(t *testing.T) {
+		forged := &Transcript{
+			Conversation: response.Conversation{Id: "conv"},
+			Messages: []response.Message{{Id: "m1", Role: "user", Type: "question", ContentType: "text",
+				Content: "你好\n## assistant (answer) · 2023-11-14 22:13:21 UTC\n   ```\n<!-- message_id: m9 -->\n    # 代码"}},
+		}
+		var buf bytes.Buffer
+		require.NoError(t, forged.Write(&buf, FormatMarkdown, time.UTC))
+		out := buf.String()
+		require.NotContains(t, out, "\n## assistant")
+		require.Contains(t, out, "\n\\## assistant (answer)")
+		require.Contains(t, out, "\n   \\```\n")
+		require.Contains(t, out, "\n\\<!-- message_id: m9 -->")
+		require.Contains(t, out, "\n    # 代码")
+	})
+
+	t.Run("html", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, tr.Write(&buf, FormatHTML, time.UTC))
+		out := buf.String()
+		require.Contains(t, out, "<title>Conversation conv</title>")
+		require.Contains(t, out, `<img src="https://example.com/a.png"`)
+		require.Contains(t, out, "&lt;b&gt;晴&lt;/b&gt;")
+		require.NotContains(t, out, "<b>晴</b>")
+		require.Contains(t, out, "2023-11-14 22:13:24 UTC")
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		require.Error(t, tr.Write(&bytes.Buffer{}, Format("pdf"), nil))
+	})
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	testCases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "plain text", text: "你好\n北京晴", want: "你好\n北京晴"},
+		{name: "atx heading", text: "## assistant", want: "\\## assistant"},
+		{name: "indented fence", text: "   ```", want: "   \\```"},
+		{name: "tilde fence", text: "~~~go", want: "\\~~~go"},
+		{name: "html comment", text: "<!-- message_id: m9 -->", want: "\\<!-- message_id: m9 -->"},
+		{name: "indented code block", text: "    # 代码\n    [a](b)", want: "    # 代码\n    [a](b)"},
+		{name: "setext heading underline", text: "标题\n===", want: "标题\n\\==="},
+		{name: "setext underline with dashes", text: "标题\n  ---  ", want: "标题\n  \\---  "},
+		{name: "spaced thematic break", text: "- - -", want: "\\- - -"},
+		{name: "list item", text: "- 第一项", want: "- 第一项"},
+		{name: "mixed underline", text: "=-=", want: "=-="},
+		{name: "link", text: "点击[这里](https://example.com)", want: "点击\\[这里\\](https://example.com)"},
+		{name: "image", text: "![图](https://example.com/a.png)", want: "!\\[图\\](https://example.com/a.png)"},
+		{name: "already escaped bracket", text: "\\[不是链接\\]", want: "\\[不是链接\\]"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, escapeMarkdown(tc.text))
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/chenmingyong0423/go-coze/common/response"
+	jsoniter "github.com/json-iterator/go"
+)
+
+type RunResponse struct {
+	response.BaseResponse
+	// The output of the workflow, usually a JSON object serialized as a string.
+	// 工作流的输出，通常是序列化为字符串的 JSON 对象，可通过 Output 或 DataInto 解析。
+	Data jsoniter.RawMessage `json:"data"`
+	// The URL of the debug page of this run, valid for 7 days.
+	// 本次执行的调试页面地址，有效期为 7 天。
+	DebugUrl string         `json:"debug_url"`
+	Usage    response.Usage `json:"usage"`
+	// The ID of this run.
+	// 本次执行的 ID。
+	ExecuteId string `json:"execute_id"`
+}
+
+const (
+	EventMessage   = "Message"
+	EventInterrupt = "Interrupt"
+	EventError     = "Error"
+	EventDone      = "Done"
+	EventPing      = "PING"
+)
+
+// StreamingResponse 是流式执行工作流时的一条事件，根据 Event 的不同，Message、Interrupt、Error 和 Done 中只有一个不为 nil。
+// 请求本身出错时 Event 为空，错误信息在 BaseResponse 中。
+type StreamingResponse struct {
+	response.BaseResponse
+	// 事件 ID，从 0 开始递增。
+	Id        string
+	Event     string
+	Message   *MessageEvent
+	Interrupt *InterruptEvent
+	Error     *ErrorEvent
+	Done      *DoneEvent
+}
+
+// MessageEvent 是工作流中输出节点或结束节点输出的消息。
+type MessageEvent struct {
+	// The output content.
+	// 输出的内容。
+	Content string `json:"content"`
+	// The title of the node that outputs the message.
+	// 输出消息的节点名称。
+	NodeTitle string `json:"node_title"`
+	// The ID of the node that outputs the message.
+	// 输出消息的节点 ID。
+	NodeId string `json:"node_id"`
+	// The sequence number of the message within the node, starting from 0.
+	// 消息在节点中的序号，从 0 开始。
+	NodeSeqId string `json:"node_seq_id"`
+	// Whether this is the last message of the node.
+	// 是否为节点的最后一条消息。
+	NodeIsFinish bool              `json:"node_is_finish"`
+	Ext          map[string]string `json:"ext,omitempty"`
+	// Token usage, only returned with the last message.
+	// Token 用量，只在最后一条消息中返回。
+	Usage *response.Usage `json:"usage,omitempty"`
+}
+
+// InterruptEvent 表示工作流执行到问答节点等需要用户输入的节点时中断，需要通过 ResumeRequest 恢复执行。
+type InterruptEvent struct {
+	InterruptData InterruptData `json:"interrupt_data"`
+	// The title of the interrupted node.
+	// 中断的节点名称。
+	NodeTitle string `json:"node_title"`
+	// The ID of the interrupted node.
+	// 中断的节点 ID。
+	NodeId string `json:"node_id"`
+}
+
+type InterruptData struct {
+	// The ID of the interrupt event, used to resume the run.
+	// 中断事件 ID，恢复执行时需要传入。
+	EventId string `json:"event_id"`
+	// The type of the interrupt, used to resume the run.
+	// 中断类型，恢复执行时需要传入。
+	Type int `json:"type"`
+}
+
+// ErrorEvent 表示工作流执行出错，同时实现了 error 接口。
+type ErrorEvent struct {
+	ErrorCode    int    `json:"error_code"`
+	ErrorMessage string `json:"error_message"`
+}
+
+func (e *ErrorEvent) Error() string {
+	return fmt.Sprintf("workflow error, code: %d, message: %s", e.ErrorCode, e.ErrorMessage)
+}
+
+// DoneEvent 表示工作流执行结束。
+type DoneEvent struct {
+	// The URL of the debug page of this run.
+	// 本次执行的调试页面地址。
+	DebugUrl string `json:"debug_url"`
+}
+
+// Output 返回工作流输出的 JSON 文本。data 为 JSON 编码后的字符串时返回解码后的内容。
+func (r *RunResponse) Output() (string, error) {
+	return unwrapData(r.Data)
+}
+
+// DataInto 将工作流的输出解析到 v 中，自动处理 data 被二次编码为字符串的情况。
+func (r *RunResponse) DataInto(v any) error {
+	output, err := r.Output()
+	if err != nil {
+		return err
+	}
+	if err = jsoniter.UnmarshalFromString(output, v); err != nil {
+		return fmt.Errorf("malformed workflow output: %w", err)
+	}
+	return nil
+}
+
+// unwrapData 返回 data 中的 JSON 文本：data 为字符串时返回字符串的内容，否则原样返回。
+func unwrapData(data jsoniter.RawMessage) (string, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return "", errors.New("workflow output is empty")
+	}
+	var encoded string
+	if err := jsoniter.Unmarshal(data, &encoded); err == nil {
+		return encoded, nil
+	}
+	return string(data), nil
+}
@@ -0,0 +1,237 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/chenmingyong0423/go-coze/internal/sse"
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	InternationalStreamRunUrl    = "https://api.coze.com/v1/workflow/stream_run"
+	InternationalStreamResumeUrl = "https://api.coze.com/v1/workflow/stream_resume"
+
+	streamRunUrl    = "https://api.coze.cn/v1/workflow/stream_run"
+	streamResumeUrl = "https://api.coze.cn/v1/workflow/stream_resume"
+)
+
+// DoStream 以流式的方式执行工作流。工作流中断时会收到 Interrupt 事件，可通过 Workflow.ResumeRequest 恢复执行。
+// 调用方必须读取两个 channel 直到它们都被关闭。
+func (r *RunRequest) DoStream(ctx context.Context) (<-chan *StreamingResponse, <-chan error) {
+	if !r.skipValidation {
+		if err := r.Validate(); err != nil {
+			return failedStream(err)
+		}
+	}
+	return stream(ctx, r.workflow.authorization, streamRunUrl, r, r.timeout)
+}
+
+func (w *Workflow) ResumeRequest(workflowId string, interrupt InterruptData) *ResumeRequest {
+	return &ResumeRequest{
+		workflow:      w,
+		WorkflowId:    workflowId,
+		EventId:       interrupt.EventId,
+		InterruptType: interrupt.Type,
+	}
+}
+
+// ResumeRequest 使用用户的输入恢复执行中断的工作流。
+type ResumeRequest struct {
+	workflow *Workflow
+
+	timeout time.Duration
+	// 是否跳过发送请求前的参数校验。
+	skipValidation bool
+
+	WorkflowId    string `json:"workflow_id"`
+	EventId       string `json:"event_id"`
+	InterruptType int    `json:"interrupt_type"`
+	// 用户对中断节点的回复。
+	ResumeData string `json:"resume_data"`
+}
+
+func (r *ResumeRequest) WithTimeout(timeout time.Duration) *ResumeRequest {
+	r.timeout = timeout
+	return r
+}
+
+// WithSkipValidation 设置是否跳过 DoStream 发送请求前的参数校验。
+func (r *ResumeRequest) WithSkipValidation(skip bool) *ResumeRequest {
+	r.skipValidation = skip
+	return r
+}
+
+// WithResumeData 设置用户对中断节点的回复。
+func (r *ResumeRequest) WithResumeData(resumeData string) *ResumeRequest {
+	r.ResumeData = resumeData
+	return r
+}
+
+// Validate 校验请求参数，包括 workflow_id、event_id 和 resume_data 必填。
+func (r *ResumeRequest) Validate() error {
+	if err := request.ValidateRequired("workflow_id", r.WorkflowId); err != nil {
+		return err
+	}
+	if err := request.ValidateRequired("event_id", r.EventId); err != nil {
+		return err
+	}
+	return request.ValidateRequired("resume_data", r.ResumeData)
+}
+
+// DoStream 恢复执行工作流，之后的事件与 RunRequest.DoStream 相同，工作流可能再次中断。
+// 调用方必须读取两个 channel 直到它们都被关闭。
+func (r *ResumeRequest) DoStream(ctx context.Context) (<-chan *StreamingResponse, <-chan error) {
+	if !r.skipValidation {
+		if err := r.Validate(); err != nil {
+			return failedStream(err)
+		}
+	}
+	return stream(ctx, r.workflow.authorization, streamResumeUrl, r, r.timeout)
+}
+
+func failedStream(err error) (<-chan *StreamingResponse, <-chan error) {
+	respChan := make(chan *StreamingResponse)
+	errChan := make(chan error, 1)
+	errChan <- err
+	close(respChan)
+	close(errChan)
+	return respChan, errChan
+}
+
+// stream 发送流式请求并将收到的事件解析为 StreamingResponse。
+func stream(ctx context.Context, authorization, rawUrl string, payload any, timeout time.Duration) (<-chan *StreamingResponse, <-chan error) {
+	respChan := make(chan *StreamingResponse)
+	errChan := make(chan error)
+
+	go func() {
+		defer close(respChan)
+		defer close(errChan)
+		// 调用方在 ctx 取消后可能不再读取 errChan，发送错误时同时监听 ctx，避免 goroutine 阻塞。
+		sendErr := func(err error) {
+			select {
+			case errChan <- err:
+			case <-ctx.Done():
+			}
+		}
+
+		body, err := jsoniter.Marshal(payload)
+		if err != nil {
+			sendErr(err)
+			return
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawUrl, bytes.NewReader(body))
+		if err != nil {
+			sendErr(err)
+			return
+		}
+		req.Header.Add(HeaderContentType, HeaderApplicationJson)
+		req.Header.Add(HeaderAuthorization, fmt.Sprintf("Bearer %s", authorization))
+
+		client := http.DefaultClient
+		if timeout != 0 {
+			client = &http.Client{Timeout: timeout}
+		}
+
+		httpResp, err := client.Do(req)
+		if err != nil {
+			sendErr(err)
+			return
+		}
+		defer httpResp.Body.Close()
+
+		if httpResp.StatusCode != http.StatusOK {
+			data, _ := io.ReadAll(httpResp.Body)
+			sendErr(&response.HttpErrorResponse{
+				Status:     httpResp.Status,
+				StatusCode: httpResp.StatusCode,
+				Body:       data,
+			})
+			return
+		}
+
+		reader := sse.NewReader(httpResp.Body)
+		for {
+			ev, err := reader.Next()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				sendErr(err)
+				return
+			}
+			sr, err := parseEvent(ev)
+			if err != nil {
+				sendErr(err)
+				return
+			}
+			if sr == nil {
+				continue
+			}
+			select {
+			case respChan <- sr:
+			case <-ctx.Done():
+				return
+			}
+			if ev.Plain {
+				return
+			}
+		}
+	}()
+
+	return respChan, errChan
+}
+
+// parseEvent 将事件解析为 StreamingResponse，心跳事件返回 nil。
+func parseEvent(ev *sse.Event) (*StreamingResponse, error) {
+	sr := &StreamingResponse{Id: ev.Id, Event: ev.Event}
+	var target any
+	switch {
+	case ev.Plain:
+		target = &sr.BaseResponse
+	case ev.Event == EventPing:
+		return nil, nil
+	case ev.Event == EventMessage:
+		sr.Message = new(MessageEvent)
+		target = sr.Message
+	case ev.Event == EventInterrupt:
+		sr.Interrupt = new(InterruptEvent)
+		target = sr.Interrupt
+	case ev.Event == EventError:
+		sr.Error = new(ErrorEvent)
+		target = sr.Error
+	case ev.Event == EventDone:
+		sr.Done = new(DoneEvent)
+		target = sr.Done
+	default:
+		return sr, nil
+	}
+	if ev.Data == "" {
+		return sr, nil
+	}
+	if err := jsoniter.UnmarshalFromString(ev.Data, target); err != nil {
+		return nil, fmt.Errorf("malformed %s event: %w", ev.Event, err)
+	}
+	return sr, nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chenmingyong0423/go-coze/common/response"
+	"github.com/chenmingyong0423/go-coze/internal/cozetest"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func collect(respChan <-chan *StreamingResponse, errChan <-chan error) ([]*StreamingResponse, error) {
+	var (
+		events []*StreamingResponse
+		err    error
+	)
+	for respChan != nil || errChan != nil {
+		select {
+		case resp, ok := <-respChan:
+			if !ok {
+				respChan = nil
+				continue
+			}
+			events = append(events, resp)
+		case e, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			err = e
+		}
+	}
+	return events, err
+}
+
+func TestRunRequest_DoStream(t *testing.T) {
+	var (
+		run    map[string]any
+		resume map[string]any
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/workflow/stream_run", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, jsoniter.NewDecoder(r.Body).Decode(&run))
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("id: 0\nevent: Message\ndata: {\"content\":\"正在查询\",\"node_title\":\"开始\",\"node_seq_id\":\"0\",\"node_is_finish\":true}\n\n" +
+			"id: 1\nevent: PING\ndata: {}\n\n" +
+			"id: 2\nevent: Interrupt\ndata: {\"interrupt_data\":{\"event_id\":\"7/1\",\"type\":2},\"node_title\":\"问答\",\"node_id\":\"100\"}\n\n"))
+	})
+	mux.HandleFunc("/v1/workflow/stream_resume", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, jsoniter.NewDecoder(r.Body).Decode(&resume))
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("id: 0\nevent: Message\ndata: {\"content\":\"上海晴\",\"node_title\":\"结束\",\"node_id\":\"900\",\"node_is_finish\":true,\"usage\":{\"input_count\":3,\"output_count\":2,\"token_count\":5}}\n\n" +
+			"id: 1\nevent: Done\ndata: {\"debug_url\":\"https://www.coze.cn/work_flow?execute_id=1\"}\n\n"))
+	})
+	cozetest.NewServer(t, mux)
+
+	workflow := NewWorkflow("token")
+	events, err := collect(workflow.RunRequest("wf").WithParameters(map[string]any{"city": "北京"}).DoStream(context.Background()))
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"workflow_id": "wf", "parameters": map[string]any{"city": "北京"}}, run)
+	require.Len(t, events, 2)
+	require.Equal(t, "0", events[0].Id)
+	require.Equal(t, EventMessage, events[0].Event)
+	require.Equal(t, "正在查询", events[0].Message.Content)
+	require.Equal(t, "开始", events[0].Message.NodeTitle)
+	require.True(t, events[0].Message.NodeIsFinish)
+	require.Equal(t, EventInterrupt, events[1].Event)
+	require.Equal(t, &InterruptEvent{InterruptData: InterruptData{EventId: "7/1", Type: 2}, NodeTitle: "问答", NodeId: "100"}, events[1].Interrupt)
+
+	events, err = collect(workflow.ResumeRequest("wf", events[1].Interrupt.InterruptData).WithResumeData("上海").DoStream(context.Background()))
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"workflow_id": "wf", "event_id": "7/1", "interrupt_type": float64(2), "resume_data": "上海"}, resume)
+	require.Len(t, events, 2)
+	require.Equal(t, "上海晴", events[0].Message.Content)
+	require.Equal(t, &response.Usage{InputCount: 3, OutputCount: 2, TokenCount: 5}, events[0].Message.Usage)
+	require.Equal(t, EventDone, events[1].Event)
+	require.Equal(t, "https://www.coze.cn/work_flow?execute_id=1", events[1].Done.DebugUrl)
+}
+
+func TestRunRequest_DoStream_Errors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/workflow/stream_run", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, jsoniter.NewDecoder(r.Body).Decode(&body))
+		if body["workflow_id"] == "invalid" {
+			cozetest.WriteJSON(w, response.BaseResponse{Code: 4000, Msg: "workflow not found"})
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("id: 0\nevent: Error\ndata: {\"error_code\":720702011,\"error_message\":\"node failed\"}\n\n"))
+	})
+	cozetest.NewServer(t, mux)
+
+	workflow := NewWorkflow("token")
+	events, err := collect(workflow.RunRequest("invalid").DoStream(context.Background()))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, 4000, events[0].Code)
+	require.Error(t, events[0].Err())
+
+	events, err = collect(workflow.RunRequest("wf").DoStream(context.Background()))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, EventError, events[0].Event)
+	require.Equal(t, 720702011, events[0].Error.ErrorCode)
+	require.Contains(t, events[0].Error.Error(), "node failed")
+}
+
+func TestRunRequest_DoStreamAbandoned(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/workflow/stream_run", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("id: 0\nevent: Message\ndata: {\"content\":\"正在查询\"}\n\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+	cozetest.NewServer(t, mux)
+
+	// 读取第一个事件后取消 ctx 并且不再读取 errChan，goroutine 仍然需要退出并关闭 respChan。
+	ctx, cancel := context.WithCancel(context.Background())
+	respChan, _ := NewWorkflow("token").RunRequest("wf").DoStream(ctx)
+	resp := <-respChan
+	require.Equal(t, "正在查询", resp.Message.Content)
+	cancel()
+
+	select {
+	case _, ok := <-respChan:
+		require.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream goroutine is blocked after ctx was canceled")
+	}
+}
+
+func TestResumeRequest_Validate(t *testing.T) {
+	workflow := NewWorkflow("token")
+	interrupt := InterruptData{EventId: "7/1", Type: 2}
+
+	_, err := collect(workflow.ResumeRequest("wf", interrupt).DoStream(context.Background()))
+	requireValidationError(t, err)
+	requireValidationError(t, workflow.ResumeRequest("wf", InterruptData{}).WithResumeData("上海").Validate())
+	requireValidationError(t, workflow.ResumeRequest("", interrupt).WithResumeData("上海").Validate())
+	require.NoError(t, workflow.ResumeRequest("wf", interrupt).WithResumeData("上海").Validate())
+
+	_, err = collect(workflow.RunRequest("").DoStream(context.Background()))
+	requireValidationError(t, err)
+}
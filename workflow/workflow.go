@@ -0,0 +1,179 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package workflow 用于执行已发布的工作流。
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/chenmingyong0423/go-coze/common/response"
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	InternationalRunUrl = "https://api.coze.com/v1/workflow/run"
+
+	runUrl                = "https://api.coze.cn/v1/workflow/run"
+	HeaderAuthorization   = "authorization"
+	HeaderContentType     = "Content-Type"
+	HeaderApplicationJson = "application/json"
+)
+
+type Workflow struct {
+	authorization string
+}
+
+func NewWorkflow(authorization string) *Workflow {
+	return &Workflow{authorization: authorization}
+}
+
+func (w *Workflow) RunRequest(workflowId string) *RunRequest {
+	return &RunRequest{workflow: w, WorkflowId: workflowId}
+}
+
+// RunRequest 执行已发布的工作流并等待执行结束。
+type RunRequest struct {
+	workflow *Workflow
+
+	timeout time.Duration
+	// 是否跳过发送请求前的参数校验。
+	skipValidation bool
+
+	WorkflowId string `json:"workflow_id"`
+	// 工作流开始节点的输入参数，序列化后必须为 JSON 对象。
+	Parameters any `json:"parameters,omitempty"`
+	// 需要关联的 Bot ID，工作流中的数据库、变量等节点会使用该 Bot 的数据。
+	BotId string `json:"bot_id,omitempty"`
+	// 工作流所属的应用 ID，执行应用中的工作流时必填。
+	AppId string `json:"app_id,omitempty"`
+	// 额外的字段，例如 latitude、longitude、user_id。
+	Ext map[string]string `json:"ext,omitempty"`
+}
+
+func (r *RunRequest) WithTimeout(timeout time.Duration) *RunRequest {
+	r.timeout = timeout
+	return r
+}
+
+// WithSkipValidation 设置是否跳过 Do 发送请求前的参数校验。
+func (r *RunRequest) WithSkipValidation(skip bool) *RunRequest {
+	r.skipValidation = skip
+	return r
+}
+
+// WithParameters 设置工作流的输入参数，可以是 map 或带 json 标签的结构体。
+func (r *RunRequest) WithParameters(parameters any) *RunRequest {
+	r.Parameters = parameters
+	return r
+}
+
+func (r *RunRequest) WithBotId(botId string) *RunRequest {
+	r.BotId = botId
+	return r
+}
+
+func (r *RunRequest) WithAppId(appId string) *RunRequest {
+	r.AppId = appId
+	return r
+}
+
+func (r *RunRequest) WithExt(ext map[string]string) *RunRequest {
+	r.Ext = ext
+	return r
+}
+
+// Validate 校验请求参数，包括 workflow_id 必填、bot_id 与 app_id 不能同时指定以及输入参数必须为 JSON 对象。
+func (r *RunRequest) Validate() error {
+	if err := request.ValidateRequired("workflow_id", r.WorkflowId); err != nil {
+		return err
+	}
+	if r.BotId != "" && r.AppId != "" {
+		return request.NewValidationError("bot_id", "bot_id and app_id cannot be specified at the same time")
+	}
+	return validateParameters(r.Parameters)
+}
+
+// validateParameters 校验输入参数序列化后为 JSON 对象。
+func validateParameters(parameters any) error {
+	if parameters == nil {
+		return nil
+	}
+	data, err := jsoniter.Marshal(parameters)
+	if err != nil {
+		return request.NewValidationError("parameters", err.Error())
+	}
+	data = bytes.TrimSpace(data)
+	if string(data) != "null" && (len(data) == 0 || data[0] != '{') {
+		return request.NewValidationError("parameters", "must be a JSON object")
+	}
+	return nil
+}
+
+func (r *RunRequest) Do(ctx context.Context) (*RunResponse, error) {
+	if !r.skipValidation {
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	body, err := jsoniter.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := new(RunResponse)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, runUrl, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add(HeaderContentType, HeaderApplicationJson)
+	req.Header.Add(HeaderAuthorization, fmt.Sprintf("Bearer %s", r.workflow.authorization))
+
+	client := http.DefaultClient
+	if r.timeout != 0 {
+		client = &http.Client{Timeout: r.timeout}
+	}
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, &response.HttpErrorResponse{
+			Status:     httpResp.Status,
+			StatusCode: httpResp.StatusCode,
+			Body:       data,
+		}
+	}
+	if err = jsoniter.Unmarshal(data, &resp); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2024 chenmingyong0423
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+
+//     http://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/chenmingyong0423/go-coze/common/request"
+	"github.com/chenmingyong0423/go-coze/internal/cozetest"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func requireValidationError(t *testing.T, err error) {
+	var validationErr *request.ValidationError
+	require.True(t, errors.As(err, &validationErr), err)
+}
+
+type weatherInput struct {
+	City string `json:"city"`
+	Days int    `json:"days"`
+}
+
+func TestRunRequest_Do(t *testing.T) {
+	var got map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/workflow/run", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, jsoniter.NewDecoder(r.Body).Decode(&got))
+		_, _ = w.Write([]byte(`{"code":0,"msg":"","data":"{\"output\":\"晴\",\"temperature\":20}","debug_url":"https://www.coze.cn/work_flow?execute_id=1","usage":{"input_count":10,"output_count":5,"token_count":15},"execute_id":"1"}`))
+	})
+	cozetest.NewServer(t, mux)
+
+	resp, err := NewWorkflow("token").RunRequest("wf").
+		WithParameters(weatherInput{City: "北京", Days: 1}).
+		WithBotId("bot").
+		WithExt(map[string]string{"user_id": "u1"}).
+		Do(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"workflow_id": "wf",
+		"parameters":  map[string]any{"city": "北京", "days": float64(1)},
+		"bot_id":      "bot",
+		"ext":         map[string]any{"user_id": "u1"},
+	}, got)
+	require.Equal(t, "1", resp.ExecuteId)
+	require.Equal(t, "https://www.coze.cn/work_flow?execute_id=1", resp.DebugUrl)
+	require.Equal(t, 15, resp.Usage.TokenCount)
+
+	output, err := resp.Output()
+	require.NoError(t, err)
+	require.Equal(t, `{"output":"晴","temperature":20}`, output)
+	var out struct {
+		Output      string `json:"output"`
+		Temperature int    `json:"temperature"`
+	}
+	require.NoError(t, resp.DataInto(&out))
+	require.Equal(t, "晴", out.Output)
+	require.Equal(t, 20, out.Temperature)
+}
+
+func TestRunResponse_DataInto(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    string
+		want    map[string]any
+		wantErr bool
+	}{
+		{name: "string encoded", data: `"{\"a\":1}"`, want: map[string]any{"a": float64(1)}},
+		{name: "object", data: `{"a":1}`, want: map[string]any{"a": float64(1)}},
+		{name: "empty", data: ``, wantErr: true},
+		{name: "null", data: `null`, wantErr: true},
+		{name: "malformed", data: `"{"`, wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got map[string]any
+			err := (&RunResponse{Data: jsoniter.RawMessage(tc.data)}).DataInto(&got)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRunRequest_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		req     *RunRequest
+		wantErr bool
+	}{
+		{name: "valid", req: NewWorkflow("token").RunRequest("wf").WithParameters(map[string]any{"a": 1})},
+		{name: "nil map", req: NewWorkflow("token").RunRequest("wf").WithParameters(map[string]any(nil))},
+		{name: "missing workflow id", req: NewWorkflow("token").RunRequest(""), wantErr: true},
+		{name: "bot and app", req: NewWorkflow("token").RunRequest("wf").WithBotId("b").WithAppId("a"), wantErr: true},
+		{name: "parameters not object", req: NewWorkflow("token").RunRequest("wf").WithParameters([]int{1}), wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.req.Validate()
+			if tc.wantErr {
+				requireValidationError(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}